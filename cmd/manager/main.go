@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -48,6 +49,7 @@ import (
 )
 
 var iamServicePort string
+var resyncPeriod time.Duration
 
 // Change below variables to serve metrics on different host or port.
 var (
@@ -74,6 +76,8 @@ func init() {
 	// controller-runtime)
 	flagSet.AddGoFlagSet(flag.CommandLine)
 	flag.StringVar(&iamServicePort, "iam-service-port", conf.IAMServicePort, "Set iam service port")
+	flagSet.DurationVar(&resyncPeriod, conf.ResyncPeriodName, 0,
+		"Reconcile every Grafana CR at this interval even without changes, 0 to rely on watches only")
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -99,6 +103,9 @@ func main() {
 
 	newConfig := conf.GetControllerConfig()
 	newConfig.AddConfigItem(conf.IAMServicePortName, iamServicePort)
+	if resyncPeriod > 0 {
+		newConfig.AddConfigItem(conf.ResyncPeriodName, resyncPeriod)
+	}
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -17,7 +17,9 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Status describe status message of grafana
@@ -37,7 +39,7 @@ type GrafanaSpec struct {
 	BaseImage                   string                   `json:"baseImage,omitempty"`
 	BaseImageTag                string                   `json:"baseImageTag,omitempty"`
 	BaseImageSHA                string                   `json:"baseImageSHA,omitempty"`
-	Resources                   *GrafanaResources        `json:"resources,omitempty"`
+	Resources                   *GrafanaResources        `json:"resources,omitempty"` // Deprecated: use Size
 	PersistentVolume            *GrafanaPersistentVolume `json:"persistentVolume,omitempty"`
 	IsHub                       bool                     `json:"isHub,omitempty"`
 	IPVersion                   string                   `json:"ipVersion,omitempty"`
@@ -60,13 +62,326 @@ type GrafanaSpec struct {
 	RouterConfig                *RouterConfig            `json:"routerConfig,omitempty"`
 	DataSourceConfig            *DataSourceConfig        `json:"datasourceConfig,omitempty"`
 	NodeSelector                map[string]string        `json:"nodeSelector,omitempty"`
+	// Tolerations of the grafana pods, e.g. to run on tainted infra nodes
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the grafana pods. With several replicas the pods prefer
+	// distinct nodes unless a pod anti affinity is set.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints of the grafana pods, the constraints without
+	// a label selector select the pods of this instance
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName of the grafana pods
+	// +kubebuilder:validation:MaxLength=253
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Replicas is the number of grafana pods, more than one requires an external database.
+	// It is ignored when spec.autoscaling.hpa is set.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Database stores the grafana state, sqlite3 on the grafana-storage volume by default
+	Database *GrafanaDatabase `json:"database,omitempty"`
+	// AdminSecretRef names a secret with the username and password keys of the grafana admin.
+	// By default the operator generates the secret with a random password.
+	AdminSecretRef *corev1.LocalObjectReference `json:"adminSecretRef,omitempty"`
+	// AdminCredentialRotation schedules the rotation of the generated admin password
+	AdminCredentialRotation *AdminCredentialRotation `json:"adminCredentialRotation,omitempty"`
+	// Auth selects how users log in to grafana, through the IAM router by default
+	Auth *GrafanaAuth `json:"auth,omitempty"`
+	// SMTP is the mail server grafana sends alert notifications and invitations through
+	SMTP *GrafanaSMTP `json:"smtp,omitempty"`
+	// Plugins are installed before grafana starts
+	Plugins []GrafanaPlugin `json:"plugins,omitempty"`
+	// SecurityContext is merged over the restricted security profile of the grafana pods
+	SecurityContext *SecurityContextConfig `json:"securityContext,omitempty"`
+	// Size sets the resources of all the containers, small by default. While it is
+	// not set, the deprecated resource fields still set the resources of their containers.
+	// +kubebuilder:validation:Enum=small;medium;large;custom
+	Size string `json:"size,omitempty"`
+	// CustomResources are the resources of the containers with the custom size,
+	// the containers left out get the resources of the small size
+	CustomResources *ContainerResources `json:"customResources,omitempty"`
+	// Autoscaling lets the operator create autoscalers for the grafana deployment
+	Autoscaling *GrafanaAutoscaling `json:"autoscaling,omitempty"`
+	// PodDisruptionBudget limits the grafana pods evicted at once by node drains
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
 }
 
+// PodDisruptionBudgetConfig sets one of MinAvailable and MaxUnavailable,
+// a single pod can be unavailable by default. No budget is created when
+// it would block node drains, e.g. with a single replica.
+type PodDisruptionBudgetConfig struct {
+	// MinAvailable is a number or a percentage of the grafana pods
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable is a number or a percentage of the grafana pods
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// GrafanaAutoscaling defines the autoscalers of the grafana deployment
+type GrafanaAutoscaling struct {
+	// HPA scales the number of grafana pods, spec.replicas is ignored when it is set
+	HPA *HorizontalAutoscaling `json:"hpa,omitempty"`
+	// VPA recommends or sets the resources of the grafana containers.
+	// It requires the VerticalPodAutoscaler CRD in the cluster.
+	VPA *VerticalAutoscaling `json:"vpa,omitempty"`
+}
+
+// HorizontalAutoscaling defines the HorizontalPodAutoscaler of the grafana deployment.
+// It scales on CPU at 80% of the requests when no target is set.
+type HorizontalAutoscaling struct {
+	// MinReplicas is 1 by default
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas, more than one requires an external database
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilization is the average CPU usage of the pods in percent of their requests
+	// +kubebuilder:validation:Minimum=1
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+	// TargetMemoryUtilization is the average memory usage of the pods in percent of their requests
+	// +kubebuilder:validation:Minimum=1
+	TargetMemoryUtilization *int32 `json:"targetMemoryUtilization,omitempty"`
+}
+
+// VerticalAutoscaling defines the VerticalPodAutoscaler of the grafana deployment
+type VerticalAutoscaling struct {
+	// Mode is recommend or auto, recommend by default. Recommend only reports the
+	// resources in the status of the VPA, auto applies them when it recreates the pods.
+	// +kubebuilder:validation:Enum=recommend;auto
+	Mode string `json:"mode,omitempty"`
+	// MinAllowed are the lowest resources the VPA sets on a container
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// MaxAllowed are the highest resources the VPA sets on a container
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+}
+
+// Modes of VerticalAutoscaling
+const (
+	VPAModeRecommend = "recommend"
+	VPAModeAuto      = "auto"
+)
+
+// Sizes of spec.size
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+	SizeCustom = "custom"
+)
+
+// ContainerResources are the resources of each container of the grafana pods
+type ContainerResources struct {
+	Grafana   *corev1.ResourceRequirements `json:"grafana,omitempty"`
+	Router    *corev1.ResourceRequirements `json:"router,omitempty"`
+	Dashboard *corev1.ResourceRequirements `json:"dashboard,omitempty"`
+	DSProxy   *corev1.ResourceRequirements `json:"dsProxy,omitempty"`
+	// Init is also used to install the plugins
+	Init *corev1.ResourceRequirements `json:"init,omitempty"`
+}
+
+// SecurityContextConfig overrides the restricted security profile. The fields
+// set here replace the ones of the profile, the others are kept.
+type SecurityContextConfig struct {
+	// Pod is merged over the pod security context
+	Pod *corev1.PodSecurityContext `json:"pod,omitempty"`
+	// Containers are merged over the security context of the container of the same name
+	Containers map[string]corev1.SecurityContext `json:"containers,omitempty"`
+}
+
+// GrafanaPlugin is a plugin installed from grafana.com, a URL, or a zip
+// provided in a configmap or a persistent volume claim. At most one of
+// URL, ConfigMap and PersistentVolumeClaim is set.
+type GrafanaPlugin struct {
+	// Name is the plugin id, e.g. grafana-piechart-panel
+	Name string `json:"name"`
+	// Version of the plugin, the latest one when empty
+	Version string `json:"version,omitempty"`
+	// URL of the plugin zip
+	URL string `json:"url,omitempty"`
+	// ConfigMap holds the plugin zip under a binary data key
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+	// PersistentVolumeClaim holds the plugin zip
+	PersistentVolumeClaim *PluginClaimSource `json:"persistentVolumeClaim,omitempty"`
+	// AllowUnsigned lets grafana load the plugin without a valid signature
+	AllowUnsigned bool `json:"allowUnsigned,omitempty"`
+}
+
+// PluginClaimSource is a plugin zip in a persistent volume claim
+type PluginClaimSource struct {
+	ClaimName string `json:"claimName"`
+	// Path of the zip in the volume
+	Path string `json:"path"`
+}
+
+// GrafanaSMTP defines the mail server of grafana
+type GrafanaSMTP struct {
+	// Host is the host:port of the SMTP server, port 25 by default
+	Host string `json:"host"`
+	// FromAddress is the sender address of the emails
+	FromAddress string `json:"fromAddress"`
+	// FromName is the sender name of the emails
+	FromName string `json:"fromName,omitempty"`
+	// SkipVerify does not verify the certificate of the SMTP server
+	SkipVerify bool `json:"skipVerify,omitempty"`
+	// EHLOIdentity is the name sent in the EHLO command, the grafana host name by default
+	EHLOIdentity string `json:"ehloIdentity,omitempty"`
+	// SecretRef names a secret with the username and password keys of the SMTP server
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+	// StartTLSPolicy is OpportunisticStartTLS, MandatoryStartTLS or NoStartTLS,
+	// OpportunisticStartTLS by default
+	StartTLSPolicy string `json:"startTLSPolicy,omitempty"`
+	// CertSecretRef names a secret with the tls.crt and tls.key keys of the client
+	// certificate presented to the SMTP server. The certificate of the server is
+	// verified against the CAs of the grafana image, unless SkipVerify is set.
+	CertSecretRef *corev1.LocalObjectReference `json:"certSecretRef,omitempty"`
+}
+
+// STARTTLS policies of GrafanaSMTP
+const (
+	SMTPOpportunisticStartTLS = "OpportunisticStartTLS"
+	SMTPMandatoryStartTLS     = "MandatoryStartTLS"
+	SMTPNoStartTLS            = "NoStartTLS"
+)
+
+// GrafanaAuth defines how users log in to grafana
+type GrafanaAuth struct {
+	// Mode is iam-router, oidc or proxy-header
+	Mode string `json:"mode,omitempty"`
+	// RootURL is the external URL of grafana, the OIDC provider redirects to it.
+	// Its path is the ingress path: /grafana for the CR named ibm-monitoring,
+	// /grafana-<name> for the others.
+	RootURL string `json:"rootURL,omitempty"`
+	// OIDC configures the login through an OpenID Connect provider
+	OIDC *OIDCAuth `json:"oidc,omitempty"`
+	// ProxyHeader configures the login through an authenticating proxy
+	ProxyHeader *ProxyHeaderAuth `json:"proxyHeader,omitempty"`
+	// RoleMapping maps the IAM role of a user in a namespace to a grafana org role
+	// in the iam-router mode. Users matching no entry are viewers.
+	RoleMapping []RoleMapping `json:"roleMapping,omitempty"`
+	// UserSync configures how the operator syncs the IAM users into the grafana orgs in the iam-router mode
+	UserSync *UserSync `json:"userSync,omitempty"`
+	// StaleUserCleanup removes the grafana users which are no longer IAM users in the iam-router mode
+	StaleUserCleanup *StaleUserCleanup `json:"staleUserCleanup,omitempty"`
+}
+
+// StaleUserCleanup defines the periodic cleanup of the grafana users
+// whose IAM user was deleted, in the iam-router mode only. It replaces the
+// /check_stale_users location of the router, which now answers 404.
+type StaleUserCleanup struct {
+	// Interval between two cleanups, 24h by default
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Action is delete or disable, delete by default
+	Action string `json:"action,omitempty"`
+	// DryRun only reports the stale users
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Actions of StaleUserCleanup
+const (
+	StaleUserDelete  = "delete"
+	StaleUserDisable = "disable"
+)
+
+// UserSync defines the sync of the IAM users and of their namespaces into
+// grafana users and orgs, one org per namespace. A new IAM user, or a user
+// given access to a new namespace, gets it in grafana once the next sync ran,
+// up to Interval later. Setting the SyncUsersAnnotation annotation to a new
+// value syncs the users right away.
+type UserSync struct {
+	// Interval between two syncs, 5m by default
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// CredentialsSecretRef names a secret with the admin_username and admin_password keys
+	// of the IAM admin, platform-auth-idp-credentials by default
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// SyncUsersAnnotation syncs the IAM users into grafana every time its value changes
+const SyncUsersAnnotation = "operator.ibm.com/sync-users"
+
+// RoleMapping maps either an IAM role or the IAM actions of a user to a grafana role
+type RoleMapping struct {
+	// IAMRole is the highest IAM role of the user in the namespace, such as Operator
+	IAMRole string `json:"iamRole,omitempty"`
+	// Actions are the IAM actions of the user when IAM reports no role, such as CRUD
+	Actions string `json:"actions,omitempty"`
+	// GrafanaRole is Admin, Editor or Viewer
+	GrafanaRole string `json:"grafanaRole"`
+}
+
+// Grafana org roles
+const (
+	GrafanaRoleAdmin  = "Admin"
+	GrafanaRoleEditor = "Editor"
+	GrafanaRoleViewer = "Viewer"
+)
+
+// OIDCAuth defines the grafana generic OAuth client
+type OIDCAuth struct {
+	// Name is shown on the login button
+	Name     string `json:"name,omitempty"`
+	ClientID string `json:"clientID,omitempty"`
+	// ClientSecretRef selects the key of a secret holding the client secret
+	ClientSecretRef *corev1.SecretKeySelector `json:"clientSecretRef,omitempty"`
+	AuthURL         string                    `json:"authURL,omitempty"`
+	TokenURL        string                    `json:"tokenURL,omitempty"`
+	APIURL          string                    `json:"apiURL,omitempty"`
+	// Scopes requested, openid profile email by default
+	Scopes []string `json:"scopes,omitempty"`
+	// RoleAttributePath is a JMESPath expression mapping the user info to a grafana role
+	RoleAttributePath string `json:"roleAttributePath,omitempty"`
+}
+
+// ProxyHeaderAuth defines the header an authenticating proxy sets. The
+// operator creates no ingress in this mode, the proxy reaches the grafana service.
+type ProxyHeaderAuth struct {
+	// HeaderName holds the user name, X-WEBAUTH-USER by default
+	HeaderName string `json:"headerName,omitempty"`
+	// HeaderProperty is username or email
+	HeaderProperty string `json:"headerProperty,omitempty"`
+	// Whitelist are the IP addresses or CIDRs of the authenticating proxy,
+	// the header is only accepted from them. It is required.
+	Whitelist []string `json:"whitelist,omitempty"`
+}
+
+// Modes of GrafanaAuth
+const (
+	AuthModeIAMRouter   = "iam-router"
+	AuthModeOIDC        = "oidc"
+	AuthModeProxyHeader = "proxy-header"
+)
+
+// AdminCredentialRotation defines when the generated admin password is changed.
+// Setting the RotateAdminCredentialAnnotation annotation to a new value
+// also rotates it right away.
+type AdminCredentialRotation struct {
+	// Interval between two rotations, such as 720h
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// RotateAdminCredentialAnnotation rotates the generated admin password every time its value changes
+const RotateAdminCredentialAnnotation = "operator.ibm.com/rotate-admin-credential"
+
+// GrafanaDatabase defines the database storing users, orgs, dashboards and login sessions
+type GrafanaDatabase struct {
+	// Type is sqlite3, mysql or postgres
+	Type string `json:"type,omitempty"`
+	// Host is the host:port of the mysql or postgres server
+	Host string `json:"host,omitempty"`
+	// Name is the name of the database
+	Name string `json:"name,omitempty"`
+	// SecretRef names a secret with the username and password keys
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// Database types supported by GrafanaDatabase
+const (
+	DatabaseSQLite   = "sqlite3"
+	DatabaseMySQL    = "mysql"
+	DatabasePostgres = "postgres"
+)
+
 // DataSourceConfig defines Grafana datasource configurations
 // Datasource defined here should be Prometheus or 'as-is' prometheus like thanos-querier
 type DataSourceConfig struct {
 	OCPDSConfig    *OCPDSConfig                 `json:"openshift,omitempty"`
-	ProxyResources *corev1.ResourceRequirements `json:"proxyResources,omitempty"`
+	ProxyResources *corev1.ResourceRequirements `json:"proxyResources,omitempty"` // Deprecated: use Size
 }
 
 // OCPDSConfig defines openshift application monitoring datasource configurations
@@ -81,9 +396,10 @@ type DashboardConfig struct {
 	IPVersion        string                       `json:"ipVersion,omitempty"`
 	MainOrg          string                       `json:"mainOrg,omitempty"`
 	DashboardsStatus map[string]bool              `json:"dashboardsStatus,omitempty"`
-	Resources        *corev1.ResourceRequirements `json:"resources,omitempty"`
+	Resources        *corev1.ResourceRequirements `json:"resources,omitempty"` // Deprecated: use Size
 }
 
+// GrafanaResources multiplies the default resources of the containers
 type GrafanaResources struct {
 	Grafana   int `json:"grafana,omitempty"`
 	Dashboard int `json:"dashboard,omitempty"`
@@ -101,18 +417,33 @@ type GrafanaService struct {
 
 type GrafanaConfig struct {
 	StorageClass          string                       `json:"storageClass,omitempty"`
-	Resources             *corev1.ResourceRequirements `json:"resources,omitempty"`
+	Resources             *corev1.ResourceRequirements `json:"resources,omitempty"` // Deprecated: use Size
 	PersistentVolumeClaim string                       `json:"persistentVolumeClaim,omitempty"`
+	// INI is merged over the grafana.ini of the operator, by section and key.
+	// The keys the operator relies on, such as the cert paths, can not be changed.
+	INI map[string]INISection `json:"ini,omitempty"`
 }
 
+// INISection is the keys and values of a grafana.ini section
+type INISection map[string]string
+
 type RouterConfig struct {
-	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"` // Deprecated: use Size
 }
 
 // GrafanaPersistentVolume setup persistent volumes.
+// When ClaimName is empty the operator creates the claim itself.
 type GrafanaPersistentVolume struct {
 	Enabled   bool   `json:"enabled,omitempty"`
 	ClaimName string `json:"claimName,omitempty"`
+	// Size of the claim created by the operator, 1Gi by default. It can only grow.
+	Size *resource.Quantity `json:"size,omitempty"`
+	// StorageClass of the claim created by the operator
+	StorageClass string `json:"storageClass,omitempty"`
+	// AccessMode of the claim created by the operator, ReadWriteOnce by default
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	// ReclaimPolicy Retain keeps the claim created by the operator when the CR is deleted
+	ReclaimPolicy corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
 }
 
 // GrafanaStatus defines the observed state of Grafana
@@ -122,8 +453,106 @@ type GrafanaStatus struct {
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.htm
 	Phase   Status `json:"phase"`
 	Message string `json:"message"`
+	// ObservedGeneration is the most recent generation handled by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the latest observations of each reconcile step
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// AdminCredential reports the rotations of the generated admin password
+	AdminCredential *AdminCredentialStatus `json:"adminCredential,omitempty"`
+	// RoleMapping is the mapping of IAM roles to grafana roles the user sync applies
+	RoleMapping []RoleMapping `json:"roleMapping,omitempty"`
+	// LastUserSyncTime is when the IAM users were last synced into grafana
+	LastUserSyncTime *metav1.Time `json:"lastUserSyncTime,omitempty"`
+	// LastUserSyncTrigger is the value of the sync annotation last handled
+	LastUserSyncTrigger string `json:"lastUserSyncTrigger,omitempty"`
+	// LastStaleUserCleanupTime is when the stale grafana users were last looked for
+	LastStaleUserCleanupTime *metav1.Time `json:"lastStaleUserCleanupTime,omitempty"`
+	// Plugins are the plugins grafana loaded, core plugins excluded
+	Plugins []InstalledPlugin `json:"plugins,omitempty"`
+	// Health is what grafana reports through its health endpoint
+	Health *GrafanaHealthStatus `json:"health,omitempty"`
+}
+
+// GrafanaHealthStatus defines the observed health of the running grafana
+type GrafanaHealthStatus struct {
+	// Database is ok once grafana reaches its database and migrated it
+	Database string `json:"database,omitempty"`
+	// Version of the running grafana
+	Version string `json:"version,omitempty"`
+	// Commit grafana was built from
+	Commit string `json:"commit,omitempty"`
+	// LastCheckTime is when the health check last succeeded
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// ConsecutiveFailures counts the health checks failed since the last successful one
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
 }
 
+// AdminCredentialStatus defines the observed state of the admin password
+type AdminCredentialStatus struct {
+	// LastRotationTime is when the admin password was last changed
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// LastRotationTrigger is the value of the rotation annotation last handled
+	LastRotationTrigger string `json:"lastRotationTrigger,omitempty"`
+}
+
+// InstalledPlugin is a plugin loaded by grafana
+type InstalledPlugin struct {
+	ID      string `json:"id"`
+	Version string `json:"version,omitempty"`
+	// Signature is valid, unsigned, invalid or modified
+	Signature string `json:"signature,omitempty"`
+}
+
+// Condition types reported in GrafanaStatus.Conditions
+const (
+	// ConditionAvailable is true when the grafana deployment has available replicas
+	ConditionAvailable = "Available"
+	// ConditionProgressing is true while the grafana deployment is rolling out
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the last reconcile failed
+	ConditionDegraded = "Degraded"
+	// ConditionCertificateReady is true when the serving certificate is issued
+	ConditionCertificateReady = "CertificateReady"
+	// ConditionDatasourceReachable is true when the OCP monitoring datasource can serve metrics
+	ConditionDatasourceReachable = "DatasourceReachable"
+	// ConditionDashboardsSynced is true when all the default dashboards are created
+	ConditionDashboardsSynced = "DashboardsSynced"
+	// ConditionTerminating is true while the resources of a deleted CR are cleaned up
+	ConditionTerminating = "Terminating"
+	// ConditionSpecValid is false when the spec asks for an unsafe or unsupported setup
+	ConditionSpecValid = "SpecValid"
+	// ConditionStorageReady is true when the claim created by the operator is bound with the requested size
+	ConditionStorageReady = "StorageReady"
+	// ConditionDatabaseReachable is true when the external database accepts connections from the operator
+	ConditionDatabaseReachable = "DatabaseReachable"
+	// ConditionAdminCredentialRotated is false when the last rotation of the admin password failed
+	ConditionAdminCredentialRotated = "AdminCredentialRotated"
+	// ConditionUsersSynced is true when the last sync of the IAM users into grafana succeeded
+	ConditionUsersSynced = "UsersSynced"
+	// ConditionStaleUsersCleaned is false when the last cleanup of the stale grafana users failed
+	ConditionStaleUsersCleaned = "StaleUsersCleaned"
+	// ConditionSMTPReachable is true when the SMTP server accepts connections from the operator
+	ConditionSMTPReachable = "SMTPReachable"
+	// ConditionSecurityProfileRestricted is false when spec.securityContext weakens the restricted profile
+	ConditionSecurityProfileRestricted = "SecurityProfileRestricted"
+	// ConditionAutoscalingReady is true when the autoscalers of spec.autoscaling are created
+	ConditionAutoscalingReady = "AutoscalingReady"
+	// ConditionPodDisruptionBudgetReady is false when no budget protects the grafana pods
+	ConditionPodDisruptionBudgetReady = "PodDisruptionBudgetReady"
+	// ConditionDeprecatedResources is true when the spec still sets the resource fields replaced by spec.size
+	ConditionDeprecatedResources = "DeprecatedResources"
+)
+
+// Phases reported in GrafanaStatus.Phase
+const (
+	PhaseReconciling Status = "reconciling"
+	PhaseRunning     Status = "running"
+	PhaseFailed      Status = "failed"
+	PhaseTerminating Status = "terminating"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Grafana is the Schema for the grafanas API
@@ -22,9 +22,93 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdminCredentialRotation) DeepCopyInto(out *AdminCredentialRotation) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminCredentialRotation.
+func (in *AdminCredentialRotation) DeepCopy() *AdminCredentialRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(AdminCredentialRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdminCredentialStatus) DeepCopyInto(out *AdminCredentialStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminCredentialStatus.
+func (in *AdminCredentialStatus) DeepCopy() *AdminCredentialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AdminCredentialStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
+	*out = *in
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Router != nil {
+		in, out := &in.Router, &out.Router
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dashboard != nil {
+		in, out := &in.Dashboard, &out.Dashboard
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DSProxy != nil {
+		in, out := &in.DSProxy, &out.DSProxy
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Init != nil {
+		in, out := &in.Init, &out.Init
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResources.
+func (in *ContainerResources) DeepCopy() *ContainerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardConfig) DeepCopyInto(out *DashboardConfig) {
 	*out = *in
@@ -85,7 +169,7 @@ func (in *Grafana) DeepCopyInto(out *Grafana) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -107,6 +191,73 @@ func (in *Grafana) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaAuth) DeepCopyInto(out *GrafanaAuth) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyHeader != nil {
+		in, out := &in.ProxyHeader, &out.ProxyHeader
+		*out = new(ProxyHeaderAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleMapping != nil {
+		in, out := &in.RoleMapping, &out.RoleMapping
+		*out = make([]RoleMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserSync != nil {
+		in, out := &in.UserSync, &out.UserSync
+		*out = new(UserSync)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaleUserCleanup != nil {
+		in, out := &in.StaleUserCleanup, &out.StaleUserCleanup
+		*out = new(StaleUserCleanup)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaAuth.
+func (in *GrafanaAuth) DeepCopy() *GrafanaAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaAutoscaling) DeepCopyInto(out *GrafanaAutoscaling) {
+	*out = *in
+	if in.HPA != nil {
+		in, out := &in.HPA, &out.HPA
+		*out = new(HorizontalAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VPA != nil {
+		in, out := &in.VPA, &out.VPA
+		*out = new(VerticalAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaAutoscaling.
+func (in *GrafanaAutoscaling) DeepCopy() *GrafanaAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaConfig) DeepCopyInto(out *GrafanaConfig) {
 	*out = *in
@@ -115,6 +266,23 @@ func (in *GrafanaConfig) DeepCopyInto(out *GrafanaConfig) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.INI != nil {
+		in, out := &in.INI, &out.INI
+		*out = make(map[string]INISection, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(INISection, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -128,6 +296,47 @@ func (in *GrafanaConfig) DeepCopy() *GrafanaConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatabase) DeepCopyInto(out *GrafanaDatabase) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatabase.
+func (in *GrafanaDatabase) DeepCopy() *GrafanaDatabase {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatabase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaHealthStatus) DeepCopyInto(out *GrafanaHealthStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaHealthStatus.
+func (in *GrafanaHealthStatus) DeepCopy() *GrafanaHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaList) DeepCopyInto(out *GrafanaList) {
 	*out = *in
@@ -164,6 +373,11 @@ func (in *GrafanaList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaPersistentVolume) DeepCopyInto(out *GrafanaPersistentVolume) {
 	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
@@ -177,6 +391,32 @@ func (in *GrafanaPersistentVolume) DeepCopy() *GrafanaPersistentVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaPlugin) DeepCopyInto(out *GrafanaPlugin) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(PluginClaimSource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPlugin.
+func (in *GrafanaPlugin) DeepCopy() *GrafanaPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaResources) DeepCopyInto(out *GrafanaResources) {
 	*out = *in
@@ -193,6 +433,32 @@ func (in *GrafanaResources) DeepCopy() *GrafanaResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaSMTP) DeepCopyInto(out *GrafanaSMTP) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CertSecretRef != nil {
+		in, out := &in.CertSecretRef, &out.CertSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSMTP.
+func (in *GrafanaSMTP) DeepCopy() *GrafanaSMTP {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaSMTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaService) DeepCopyInto(out *GrafanaService) {
 	*out = *in
@@ -220,7 +486,9 @@ func (in *GrafanaService) DeepCopyInto(out *GrafanaService) {
 	if in.Ports != nil {
 		in, out := &in.Ports, &out.Ports
 		*out = make([]v1.ServicePort, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -251,7 +519,7 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 	if in.PersistentVolume != nil {
 		in, out := &in.PersistentVolume, &out.PersistentVolume
 		*out = new(GrafanaPersistentVolume)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
@@ -285,6 +553,82 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(GrafanaDatabase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminSecretRef != nil {
+		in, out := &in.AdminSecretRef, &out.AdminSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.AdminCredentialRotation != nil {
+		in, out := &in.AdminCredentialRotation, &out.AdminCredentialRotation
+		*out = new(AdminCredentialRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(GrafanaAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(GrafanaSMTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]GrafanaPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(SecurityContextConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomResources != nil {
+		in, out := &in.CustomResources, &out.CustomResources
+		*out = new(ContainerResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(GrafanaAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -301,6 +645,41 @@ func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdminCredential != nil {
+		in, out := &in.AdminCredential, &out.AdminCredential
+		*out = new(AdminCredentialStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleMapping != nil {
+		in, out := &in.RoleMapping, &out.RoleMapping
+		*out = make([]RoleMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUserSyncTime != nil {
+		in, out := &in.LastUserSyncTime, &out.LastUserSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastStaleUserCleanupTime != nil {
+		in, out := &in.LastStaleUserCleanupTime, &out.LastStaleUserCleanupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]InstalledPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(GrafanaHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -314,6 +693,74 @@ func (in *GrafanaStatus) DeepCopy() *GrafanaStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HorizontalAutoscaling) DeepCopyInto(out *HorizontalAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilization != nil {
+		in, out := &in.TargetCPUUtilization, &out.TargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilization != nil {
+		in, out := &in.TargetMemoryUtilization, &out.TargetMemoryUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalAutoscaling.
+func (in *HorizontalAutoscaling) DeepCopy() *HorizontalAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(HorizontalAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in INISection) DeepCopyInto(out *INISection) {
+	{
+		in := &in
+		*out = make(INISection, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new INISection.
+func (in INISection) DeepCopy() INISection {
+	if in == nil {
+		return nil
+	}
+	out := new(INISection)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstalledPlugin) DeepCopyInto(out *InstalledPlugin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstalledPlugin.
+func (in *InstalledPlugin) DeepCopy() *InstalledPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(InstalledPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OCPDSConfig) DeepCopyInto(out *OCPDSConfig) {
 	*out = *in
@@ -330,6 +777,111 @@ func (in *OCPDSConfig) DeepCopy() *OCPDSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCAuth) DeepCopyInto(out *OIDCAuth) {
+	*out = *in
+	if in.ClientSecretRef != nil {
+		in, out := &in.ClientSecretRef, &out.ClientSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCAuth.
+func (in *OIDCAuth) DeepCopy() *OIDCAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginClaimSource) DeepCopyInto(out *PluginClaimSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginClaimSource.
+func (in *PluginClaimSource) DeepCopy() *PluginClaimSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginClaimSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetConfig) DeepCopyInto(out *PodDisruptionBudgetConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetConfig.
+func (in *PodDisruptionBudgetConfig) DeepCopy() *PodDisruptionBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyHeaderAuth) DeepCopyInto(out *ProxyHeaderAuth) {
+	*out = *in
+	if in.Whitelist != nil {
+		in, out := &in.Whitelist, &out.Whitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyHeaderAuth.
+func (in *ProxyHeaderAuth) DeepCopy() *ProxyHeaderAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyHeaderAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleMapping) DeepCopyInto(out *RoleMapping) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleMapping.
+func (in *RoleMapping) DeepCopy() *RoleMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouterConfig) DeepCopyInto(out *RouterConfig) {
 	*out = *in
@@ -350,3 +902,108 @@ func (in *RouterConfig) DeepCopy() *RouterConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityContextConfig) DeepCopyInto(out *SecurityContextConfig) {
+	*out = *in
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make(map[string]v1.SecurityContext, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityContextConfig.
+func (in *SecurityContextConfig) DeepCopy() *SecurityContextConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityContextConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaleUserCleanup) DeepCopyInto(out *StaleUserCleanup) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaleUserCleanup.
+func (in *StaleUserCleanup) DeepCopy() *StaleUserCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(StaleUserCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserSync) DeepCopyInto(out *UserSync) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSync.
+func (in *UserSync) DeepCopy() *UserSync {
+	if in == nil {
+		return nil
+	}
+	out := new(UserSync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerticalAutoscaling) DeepCopyInto(out *VerticalAutoscaling) {
+	*out = *in
+	if in.MinAllowed != nil {
+		in, out := &in.MinAllowed, &out.MinAllowed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxAllowed != nil {
+		in, out := &in.MaxAllowed, &out.MaxAllowed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerticalAutoscaling.
+func (in *VerticalAutoscaling) DeepCopy() *VerticalAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(VerticalAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
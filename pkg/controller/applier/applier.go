@@ -0,0 +1,196 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package applier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// HashAnnotation stores the hash of the desired state an object was last written with.
+const HashAnnotation = "operator.ibm.com/desired-state-hash"
+
+var log = logf.Log.WithName("applier")
+
+// OperationResult is the action taken by Apply
+type OperationResult string
+
+const (
+	// OperationResultNone means the live object already matched the desired state
+	OperationResultNone OperationResult = "unchanged"
+	// OperationResultCreated means the object did not exist and was created
+	OperationResultCreated OperationResult = "created"
+	// OperationResultUpdated means the live object was patched
+	OperationResultUpdated OperationResult = "updated"
+)
+
+var eventReasons = map[OperationResult]string{
+	OperationResultCreated: "Created",
+	OperationResultUpdated: "Updated",
+}
+
+// MutateFn copies the fields owned by the operator onto the live object.
+type MutateFn func(current client.Object) error
+
+// Applier creates or patches the objects owned by a Grafana CR.
+// An object is only written when the desired state changed since the
+// last write or when the live object drifted from it.
+type Applier struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// New returns an Applier sharing the reconciler's client, scheme and recorder.
+func New(c client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) *Applier {
+	return &Applier{Client: c, Scheme: scheme, Recorder: recorder}
+}
+
+// Apply creates desired when it does not exist. Otherwise it runs mutate on
+// a copy of the live object and patches it if anything really changed.
+// A nil mutate copies nothing, so only ownership and the hash are kept up to date.
+func (a *Applier) Apply(ctx context.Context, owner client.Object, desired client.Object, mutate MutateFn) (OperationResult, error) {
+	hash, err := SemanticHash(desired)
+	if err != nil {
+		return OperationResultNone, err
+	}
+	kind := a.kindOf(desired)
+	key := client.ObjectKeyFromObject(desired)
+
+	current, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return OperationResultNone, fmt.Errorf("%s %s is not a client object", kind, key)
+	}
+	if err := a.Client.Get(ctx, key, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return OperationResultNone, err
+		}
+		setHash(desired, hash)
+		if err := a.setOwner(owner, desired); err != nil {
+			return OperationResultNone, err
+		}
+		if err := a.Client.Create(ctx, desired); err != nil {
+			return OperationResultNone, err
+		}
+		a.record(owner, kind, key, OperationResultCreated)
+		return OperationResultCreated, nil
+	}
+
+	updated, _ := current.DeepCopyObject().(client.Object)
+	if mutate != nil {
+		if err := mutate(updated); err != nil {
+			return OperationResultNone, err
+		}
+	}
+	setHash(updated, hash)
+	if err := a.setOwner(owner, updated); err != nil {
+		return OperationResultNone, err
+	}
+
+	// Fields left empty by the operator are defaulted by the apiserver,
+	// so only the values the operator sets are compared.
+	if equality.Semantic.DeepDerivative(updated, current) {
+		return OperationResultNone, nil
+	}
+	if err := a.Client.Patch(ctx, updated, client.MergeFrom(current)); err != nil {
+		return OperationResultNone, err
+	}
+	a.record(owner, kind, key, OperationResultUpdated)
+	return OperationResultUpdated, nil
+}
+
+// SemanticHash returns a stable hash of the labels, annotations and content
+// of obj. Server populated metadata is not part of the hash.
+func SemanticHash(obj client.Object) (string, error) {
+	converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	// The content of an unstructured object is its own map, it is not modified
+	content := make(map[string]interface{}, len(converted))
+	for k, v := range converted {
+		content[k] = v
+	}
+	delete(content, "status")
+	metadata := map[string]interface{}{}
+	if labels := obj.GetLabels(); len(labels) != 0 {
+		metadata["labels"] = labels
+	}
+	annotations := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if k != HashAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) != 0 {
+		metadata["annotations"] = annotations
+	}
+	content["metadata"] = metadata
+
+	// encoding/json sorts map keys, so equal content gives equal bytes
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func setHash(obj client.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[HashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+func (a *Applier) setOwner(owner, obj client.Object) error {
+	// Owner references can not cross namespaces
+	if owner == nil || owner.GetNamespace() != obj.GetNamespace() {
+		return nil
+	}
+	return controllerutil.SetControllerReference(owner, obj, a.Scheme)
+}
+
+func (a *Applier) kindOf(obj client.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, a.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+	return gvk.Kind
+}
+
+func (a *Applier) record(owner client.Object, kind string, key client.ObjectKey, result OperationResult) {
+	log.Info(fmt.Sprintf("%s %s is %s.", kind, key, result))
+	if owner != nil && a.Recorder != nil {
+		a.Recorder.Eventf(owner, corev1.EventTypeNormal, kind+eventReasons[result],
+			"%s %s is %s", kind, key.Name, result)
+	}
+}
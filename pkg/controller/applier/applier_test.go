@@ -0,0 +1,248 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package applier
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func desiredConfigMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-config", Namespace: namespace, Labels: map[string]string{"app": "grafana"}},
+		Data:       map[string]string{"grafana.ini": "[log]\nmode = console\n"},
+	}
+}
+
+func copyConfigMap(desired *corev1.ConfigMap) MutateFn {
+	return func(obj client.Object) error {
+		current := obj.(*corev1.ConfigMap)
+		current.Labels = desired.Labels
+		current.Data = desired.Data
+		return nil
+	}
+}
+
+func desiredService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "grafana"},
+			Ports:    []corev1.ServicePort{{Name: "web", Port: 3000}},
+		},
+	}
+}
+
+func copyService(desired *corev1.Service) MutateFn {
+	return func(obj client.Object) error {
+		current := obj.(*corev1.Service)
+		current.Spec.Selector = desired.Spec.Selector
+		current.Spec.Ports = desired.Spec.Ports
+		return nil
+	}
+}
+
+func TestApply(t *testing.T) {
+	owner := &v1alpha1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring", UID: "uid"}}
+	configMap := func() (client.Object, MutateFn) {
+		cm := desiredConfigMap("monitoring")
+		return cm, copyConfigMap(cm)
+	}
+	service := func() (client.Object, MutateFn) {
+		svc := desiredService()
+		return svc, copyService(svc)
+	}
+	tests := []struct {
+		name string
+		// seed is applied once before the tested Apply
+		seed func() (client.Object, MutateFn)
+		// live changes the seeded object, as another writer or the apiserver would
+		live    func(obj client.Object)
+		desired func() (client.Object, MutateFn)
+		want    OperationResult
+		events  int
+		check   func(t *testing.T, obj client.Object)
+	}{
+		{
+			name:    "create with the hash and the owner",
+			desired: configMap,
+			want:    OperationResultCreated,
+			events:  1,
+			check: func(t *testing.T, obj client.Object) {
+				hash, _ := SemanticHash(desiredConfigMap("monitoring"))
+				if got := obj.GetAnnotations()[HashAnnotation]; got != hash {
+					t.Errorf("got hash %q, want %q", got, hash)
+				}
+				if !metav1.IsControlledBy(obj, owner) {
+					t.Errorf("got owners %v, want the CR as controller", obj.GetOwnerReferences())
+				}
+			},
+		},
+		{
+			name:    "unchanged",
+			seed:    configMap,
+			desired: configMap,
+			want:    OperationResultNone,
+		},
+		{
+			name: "drift of a managed field",
+			seed: configMap,
+			live: func(obj client.Object) {
+				obj.(*corev1.ConfigMap).Data["grafana.ini"] = "[log]\nmode = file\n"
+			},
+			desired: configMap,
+			want:    OperationResultUpdated,
+			events:  1,
+			check: func(t *testing.T, obj client.Object) {
+				if got := obj.(*corev1.ConfigMap).Data["grafana.ini"]; got != "[log]\nmode = console\n" {
+					t.Errorf("got grafana.ini %q, want the desired one back", got)
+				}
+			},
+		},
+		{
+			name: "changed desired state",
+			seed: configMap,
+			desired: func() (client.Object, MutateFn) {
+				cm := desiredConfigMap("monitoring")
+				cm.Data["grafana.ini"] = "[log]\nlevel = debug\n"
+				return cm, copyConfigMap(cm)
+			},
+			want:   OperationResultUpdated,
+			events: 1,
+		},
+		{
+			name: "fields defaulted by the apiserver",
+			seed: service,
+			live: func(obj client.Object) {
+				svc := obj.(*corev1.Service)
+				svc.Spec.ClusterIP = "10.0.0.1"
+				svc.Spec.Type = corev1.ServiceTypeClusterIP
+				svc.Spec.SessionAffinity = corev1.ServiceAffinityNone
+				svc.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+			},
+			desired: service,
+			want:    OperationResultNone,
+		},
+		{
+			name: "owner in another namespace",
+			desired: func() (client.Object, MutateFn) {
+				cm := desiredConfigMap("openshift-monitoring")
+				return cm, copyConfigMap(cm)
+			},
+			want:   OperationResultCreated,
+			events: 1,
+			check: func(t *testing.T, obj client.Object) {
+				if refs := obj.GetOwnerReferences(); len(refs) != 0 {
+					t.Errorf("got owners %v, want none across namespaces", refs)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).Build()
+			ctx := context.Background()
+
+			if tt.seed != nil {
+				seeded, mutate := tt.seed()
+				if _, err := New(c, scheme, nil).Apply(ctx, owner, seeded, mutate); err != nil {
+					t.Fatal(err)
+				}
+				if tt.live != nil {
+					live := seeded.DeepCopyObject().(client.Object)
+					if err := c.Get(ctx, client.ObjectKeyFromObject(seeded), live); err != nil {
+						t.Fatal(err)
+					}
+					tt.live(live)
+					if err := c.Update(ctx, live); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			desired, mutate := tt.desired()
+			key := client.ObjectKeyFromObject(desired)
+			before := desired.DeepCopyObject().(client.Object)
+			_ = c.Get(ctx, key, before)
+
+			recorder := record.NewFakeRecorder(10)
+			result, err := New(c, scheme, recorder).Apply(ctx, owner, desired, mutate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.want {
+				t.Errorf("got result %s, want %s", result, tt.want)
+			}
+			if got := len(recorder.Events); got != tt.events {
+				t.Errorf("got %d events, want %d", got, tt.events)
+			}
+
+			after := desired.DeepCopyObject().(client.Object)
+			if err := c.Get(ctx, key, after); err != nil {
+				t.Fatal(err)
+			}
+			if result == OperationResultNone && after.GetResourceVersion() != before.GetResourceVersion() {
+				t.Errorf("got resource version %s, want %s unpatched", after.GetResourceVersion(), before.GetResourceVersion())
+			}
+			if tt.check != nil {
+				tt.check(t, after)
+			}
+		})
+	}
+}
+
+func TestSemanticHash(t *testing.T) {
+	cm := desiredConfigMap("monitoring")
+	hash, err := SemanticHash(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Server populated metadata and the hash itself are left out
+	live := cm.DeepCopy()
+	live.ResourceVersion = "42"
+	live.UID = "uid"
+	live.Annotations = map[string]string{HashAnnotation: hash}
+	if got, _ := SemanticHash(live); got != hash {
+		t.Errorf("got hash %s for the live object, want %s", got, hash)
+	}
+	if len(cm.Annotations) != 0 {
+		t.Errorf("hashing changed the annotations to %v", cm.Annotations)
+	}
+
+	changed := cm.DeepCopy()
+	changed.Labels["tier"] = "frontend"
+	if got, _ := SemanticHash(changed); got == hash {
+		t.Errorf("got the same hash after a label change")
+	}
+}
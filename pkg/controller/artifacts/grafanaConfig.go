@@ -25,7 +25,12 @@ const grafanaConfig = `
     protocol = https
     domain = 127.0.0.1
     http_port = {{ .ClusterPort }}
-    root_url = %(protocol)s://%(domain)s:%(http_port)s/grafana
+    {{- if .Auth.RootURL }}
+    root_url = {{ .Auth.RootURL }}
+    {{- else }}
+    root_url = %(protocol)s://%(domain)s:%(http_port)s{{ .IngressPath }}
+    {{- end }}
+    serve_from_sub_path = {{ .ServeFromSubPath }}
     cert_file = /opt/ibm/monitoring/certs/tls.crt
     cert_key = /opt/ibm/monitoring/certs/tls.key
 
@@ -43,13 +48,68 @@ const grafanaConfig = `
 
     [auth]
     disable_login_form = true
+    {{- if eq .Auth.Mode "oidc" }}
+    oauth_auto_login = true
+
+    [auth.generic_oauth]
+    enabled = true
+    name = {{ .Auth.OIDCName }}
+    allow_sign_up = true
+    {{- with .Auth.OIDC }}
+    client_id = {{ .ClientID }}
+    auth_url = {{ .AuthURL }}
+    token_url = {{ .TokenURL }}
+    api_url = {{ .APIURL }}
+    {{- if .RoleAttributePath }}
+    role_attribute_path = {{ .RoleAttributePath }}
+    {{- end }}
+    {{- end }}
+    scopes = {{ .Auth.Scopes }}
+    {{- else }}
     disable_signout_menu = true
 
     [auth.proxy]
     enabled = true
-    header_name = X-WEBAUTH-USER
-    header_property = username
-    auto_sign_up = false
-    whitelist =
+    header_name = {{ .Auth.HeaderName }}
+    header_property = {{ .Auth.HeaderProperty }}
+    auto_sign_up = {{ .Auth.AutoSignUp }}
+    whitelist = {{ .Auth.Whitelist }}
     headers =
+    {{- end }}
+    {{- with .UnsignedPlugins }}
+
+    [plugins]
+    allow_loading_unsigned_plugins = {{ . }}
+    {{- end }}
+    {{- with .SMTP }}
+
+    [smtp]
+    enabled = true
+    host = {{ .Address }}
+    from_address = {{ .FromAddress }}
+    {{- if .FromName }}
+    from_name = {{ .FromName }}
+    {{- end }}
+    skip_verify = {{ .SkipVerify }}
+    {{- if .EHLOIdentity }}
+    ehlo_identity = {{ .EHLOIdentity }}
+    {{- end }}
+    {{- if .StartTLSPolicy }}
+    startTLS_policy = {{ .StartTLSPolicy }}
+    {{- end }}
+    {{- if .CertFile }}
+    cert_file = {{ .CertFile }}
+    key_file = {{ .KeyFile }}
+    {{- end }}
+    {{- end }}
+    {{- if .Database }}
+
+    [database]
+    type = {{ .Database.Type }}
+    host = {{ .Database.Host }}
+    name = {{ .Database.Name }}
+
+    [remote_cache]
+    type = database
+    {{- end }}
 `
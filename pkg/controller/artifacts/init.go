@@ -45,6 +45,9 @@ var GrafanaDBConfig *template.Template
 
 var GrafanaDSProxyConfig *template.Template
 
+// PluginInstall installs the plugins of the spec
+var PluginInstall *template.Template
+
 func init() {
 
 	GrafanaCRDEntry = template.Must(template.New("GE").Parse(crdEntry))
@@ -56,4 +59,5 @@ func init() {
 	GrafanaConfig = template.Must(template.New("CONFIG").Parse(grafanaConfig))
 	GrafanaDBConfig = template.Must(template.New("DBC").Parse(grafanaDBConfig))
 	GrafanaDSProxyConfig = template.Must(template.New("DSPC").Parse(grafanaDSProxyConfig))
+	PluginInstall = template.Must(template.New("PI").Parse(pluginInstall))
 }
@@ -0,0 +1,24 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package artifacts
+
+// With parameters Dir and Plugins, each with a Name, a Version and a URL
+const pluginInstall = `set -e
+{{- range .Plugins }}
+echo "Install plugin {{ .Name }}"
+grafana-cli --pluginsDir {{ $.Dir }}{{ with .URL }} --pluginUrl '{{ . }}'{{ end }} plugins install {{ .Name }}{{ with .Version }} {{ . }}{{ end }}
+{{- end }}
+`
@@ -18,6 +18,8 @@ package artifacts
 // With parameters: ClusterPort and Environment
 const routerConfig = `
     error_log stderr notice;
+    # The root filesystem is read only
+    pid /tmp/nginx.pid;
 
     events {
         worker_connections 1024;
@@ -25,6 +27,11 @@ const routerConfig = `
 
     http {
         access_log off;
+        client_body_temp_path /tmp/client_body;
+        proxy_temp_path /tmp/proxy;
+        fastcgi_temp_path /tmp/fastcgi;
+        uwsgi_temp_path /tmp/uwsgi;
+        scgi_temp_path /tmp/scgi;
 
         include /opt/ibm/router/nginx/conf/mime.types;
         default_type application/octet-stream;
@@ -70,10 +77,11 @@ const routerConfig = `
             server_name dcos.*;
             root /opt/ibm/router/nginx/html;
 
-            location /check_stale_users {
-              proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
-              proxy_set_header Host $http_host;
-              rewrite_by_lua 'grafana.check_stale_users()';
+            # Health checks of the operator, grafana answers them without login
+            location = /api/health {
+              proxy_pass https://grafana/api/health;
+              proxy_ssl_certificate     /opt/ibm/router/certs/tls.crt;
+              proxy_ssl_certificate_key /opt/ibm/router/certs/tls.key;
             }
 
             location /public {
@@ -83,7 +91,6 @@ const routerConfig = `
               proxy_ssl_certificate     /opt/ibm/router/certs/tls.crt;
               proxy_ssl_certificate_key /opt/ibm/router/certs/tls.key;
               header_filter_by_lua_block {
-                  ngx.header.Authorization = "Basic {{ .GrafanaCredential }}"
                   ngx.header["Cache-control"] = "no-cache, no-store, must-revalidate"
                   ngx.header["Pragma"] = "no-cache"
                   ngx.header["Access-Control-Allow-Credentials"] = "false"
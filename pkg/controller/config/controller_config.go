@@ -23,6 +23,7 @@ import (
 var (
 	IAMServicePortName = "iam-service-port"
 	IAMServicePort     = "4300"
+	ResyncPeriodName   = "resync-period"
 )
 
 type ControllerConfig struct {
@@ -85,6 +86,13 @@ func (c *ControllerConfig) GetConfigTimestamp(key string, defaultValue time.Time
 	return defaultValue
 }
 
+func (c *ControllerConfig) GetConfigDuration(key string, defaultValue time.Duration) time.Duration {
+	if c.HasConfigItem(key) {
+		return c.Values[key].(time.Duration)
+	}
+	return defaultValue
+}
+
 func (c *ControllerConfig) HasConfigItem(key string) bool {
 	c.Lock()
 	defer c.Unlock()
@@ -83,25 +83,29 @@ func ReconcileDashboardsStatus(cr *v1alpha1.Grafana) {
 
 }
 
-// Initialize DefaultDashboards, dashboardsData, DefaultDBsStatus
-func init() {
+// DefaultDashboardDir is where the operator image ships the dashboard json files.
+const DefaultDashboardDir = "/dashboards/"
+
+// Load initializes DefaultDashboards, dashboardsData and DefaultDBsStatus
+// from the json files in dir. It is called once when the grafana controller
+// is added to the manager.
+func Load(dashboardDir string) error {
 	DefaultDashboards = map[string]string{}
 	dashboardsData = map[string]string{}
 	DefaultDBsStatus = map[string]bool{}
 
-	dashboardDir := "/dashboards/"
 	files, err := ioutil.ReadDir(dashboardDir)
 	if err != nil {
 		log.Error(err, "Fail to read dashboard file")
-		panic(err)
+		return err
 	}
 	for _, file := range files {
 		fileName := file.Name()
-		filePath := dashboardDir + fileName
+		filePath := filepath.Join(dashboardDir, fileName)
 		jsData, err := ioutil.ReadFile(filePath)
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Fail to marshal json file %s", fileName))
-			panic(err)
+			return err
 		}
 		name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
 		dashboardsData[name] = string(jsData)
@@ -117,4 +121,5 @@ func init() {
 
 	DefaultDashboards["mcm-clusters-monitoring.json"] = dashboardsData["mcm-clusters-monitoring"]
 	DefaultDashboards["kubernetes-pod-overview.json"] = dashboardsData["kubernetes-pod-overview"]
+	return nil
 }
@@ -0,0 +1,142 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/applier"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// horizontalPodAutoscalerGVK returns the newest HPA version the cluster
+// serves among the ones the operator supports.
+func horizontalPodAutoscalerGVK(mapper meta.RESTMapper) (schema.GroupVersionKind, bool) {
+	for _, version := range utils.HorizontalPodAutoscalerVersions {
+		mapping, err := mapper.RESTMapping(utils.HorizontalPodAutoscalerGroupKind, version)
+		if err == nil {
+			return mapping.GroupVersionKind, true
+		}
+	}
+	log.Info("The cluster serves neither autoscaling/v2 nor autoscaling/v2beta2, spec.autoscaling.hpa is not supported")
+	return schema.GroupVersionKind{}, false
+}
+
+// reconcileAutoscaling creates the HPA and the VPA of spec.autoscaling and
+// deletes the ones no longer asked for. A cluster without the VPA CRD or
+// without a supported HPA version only gets the AutoscalingReady condition
+// set to false.
+func reconcileAutoscaling(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if err := reconcileHorizontalPodAutoscaler(r, cr); err != nil {
+		setCondition(cr, v1alpha1.ConditionAutoscalingReady, metav1.ConditionFalse, "ApplyFailed", err.Error())
+		return err
+	}
+	installed, err := reconcileVerticalPodAutoscaler(r, cr)
+	if err != nil {
+		setCondition(cr, v1alpha1.ConditionAutoscalingReady, metav1.ConditionFalse, "ApplyFailed", err.Error())
+		return err
+	}
+
+	var autoscalers []string
+	if utils.HorizontalAutoscalingEnabled(cr) {
+		if r.hpaGVK.Empty() {
+			setCondition(cr, v1alpha1.ConditionAutoscalingReady, metav1.ConditionFalse, "HPANotSupported",
+				"spec.autoscaling.hpa is set but the cluster serves neither autoscaling/v2 nor autoscaling/v2beta2")
+			return nil
+		}
+		autoscalers = append(autoscalers, fmt.Sprintf("HorizontalPodAutoscaler scales between %d and %d replicas",
+			utils.MinReplicas(cr), utils.MaxReplicas(cr)))
+	}
+	if utils.VerticalAutoscalingEnabled(cr) {
+		if !installed {
+			setCondition(cr, v1alpha1.ConditionAutoscalingReady, metav1.ConditionFalse, "VPANotInstalled",
+				"spec.autoscaling.vpa is set but the VerticalPodAutoscaler CRD is not installed in the cluster")
+			return nil
+		}
+		autoscalers = append(autoscalers, "VerticalPodAutoscaler is in "+utils.VPAMode(cr)+" mode")
+	}
+	if len(autoscalers) == 0 {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionAutoscalingReady)
+		return nil
+	}
+	setCondition(cr, v1alpha1.ConditionAutoscalingReady, metav1.ConditionTrue, "AutoscalersCreated",
+		strings.Join(autoscalers, ", "))
+	return nil
+}
+
+func reconcileHorizontalPodAutoscaler(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if r.hpaGVK.Empty() {
+		return nil
+	}
+	hpa, err := utils.GrafanaHorizontalPodAutoscaler(cr, r.hpaGVK)
+	if err != nil {
+		return err
+	}
+	if !utils.HorizontalAutoscalingEnabled(cr) {
+		return deleteControlledObject(r, cr, hpa)
+	}
+	_, err = r.applier.Apply(r.ctx, cr, hpa, copyLabelsAndSpec(hpa))
+	return err
+}
+
+// copyLabelsAndSpec copies the labels and the spec of an unstructured desired object
+func copyLabelsAndSpec(desired *unstructured.Unstructured) applier.MutateFn {
+	return func(obj client.Object) error {
+		current := obj.(*unstructured.Unstructured)
+		current.SetLabels(desired.GetLabels())
+		current.Object["spec"] = desired.Object["spec"]
+		return nil
+	}
+}
+
+// reconcileVerticalPodAutoscaler returns false when the VPA CRD is not installed
+func reconcileVerticalPodAutoscaler(r *ReconcileGrafana, cr *v1alpha1.Grafana) (bool, error) {
+	vpa := utils.GrafanaVerticalPodAutoscaler(cr)
+	var err error
+	if utils.VerticalAutoscalingEnabled(cr) {
+		_, err = r.applier.Apply(r.ctx, cr, vpa, copyLabelsAndSpec(vpa))
+	} else {
+		err = deleteControlledObject(r, cr, vpa)
+	}
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// deleteControlledObject deletes obj when it exists and is controlled by cr
+func deleteControlledObject(r *ReconcileGrafana, cr *v1alpha1.Grafana, obj client.Object) error {
+	err := r.client.Get(r.ctx, client.ObjectKeyFromObject(obj), obj)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(obj, cr) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.client.Delete(r.ctx, obj))
+}
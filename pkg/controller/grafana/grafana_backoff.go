@@ -0,0 +1,77 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// backoff tracks consecutive reconcile failures of each Grafana CR so that
+// the retry delay grows exponentially until the CR reconciles again.
+type backoff struct {
+	*sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+func newBackoff() *backoff {
+	return &backoff{
+		Mutex:    &sync.Mutex{},
+		failures: map[types.NamespacedName]int{},
+	}
+}
+
+// next records one more failure and returns how long to wait before retrying.
+func (b *backoff) next(key types.NamespacedName, err error) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+	b.failures[key]++
+	delay := baseDelay(err)
+	for i := 1; i < b.failures[key] && delay < utils.MaxRequeueDelay; i++ {
+		delay *= 2
+	}
+	if delay > utils.MaxRequeueDelay {
+		delay = utils.MaxRequeueDelay
+	}
+	return delay
+}
+
+func (b *backoff) reset(key types.NamespacedName) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.failures, key)
+}
+
+// baseDelay is the first retry delay for an error. Transient apiserver
+// errors are retried quickly, errors that need a user to fix the CR or
+// the cluster are retried slowly.
+func baseDelay(err error) time.Duration {
+	switch {
+	case errors.IsServerTimeout(err), errors.IsTimeout(err), errors.IsTooManyRequests(err),
+		errors.IsServiceUnavailable(err), errors.IsInternalError(err):
+		return utils.MinRequeueDelay
+	case errors.IsInvalid(err), errors.IsBadRequest(err), errors.IsForbidden(err),
+		errors.IsUnauthorized(err), errors.IsNotFound(err):
+		return utils.ConfigErrorRequeueDelay
+	default:
+		return utils.RequeueDelay
+	}
+}
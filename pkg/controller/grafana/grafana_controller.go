@@ -18,24 +18,34 @@ package grafana
 import (
 	"context"
 
+	cert "github.com/ibm/ibm-cert-manager-operator/apis/certmanager/v1alpha1"
 	dbv1 "github.ibm.com/IBMPrivateCloud/grafana-dashboard-crd/pkg/apis/monitoringcontroller/v1"
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	ingressv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	secv1client "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/applier"
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/config"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/dashboards"
 	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
 )
 
@@ -49,21 +59,45 @@ var log = logf.Log.WithName("controller_grafana")
 // Add creates a new Grafana Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	if err := dashboards.Load(dashboards.DefaultDashboardDir); err != nil {
+		return err
+	}
+	r := newReconciler(mgr)
+	// The background tasks of the reconciler stop with the manager
+	err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		r.cancel()
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	context := context.Background()
+func newReconciler(mgr manager.Manager) *ReconcileGrafana {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasksDone := make(chan event.GenericEvent)
 	config := config.GetControllerConfig()
+	recorder := mgr.GetEventRecorderFor("ibm-monitoring-grafana")
+	hpaGVK, _ := horizontalPodAutoscalerGVK(mgr.GetRESTMapper())
 	return &ReconcileGrafana{
-		client:    mgr.GetClient(),
-		scheme:    mgr.GetScheme(),
-		ctx:       context,
-		config:    config,
-		kclient:   mgr.GetAPIReader(),
-		secClient: secv1client.NewForConfigOrDie(mgr.GetConfig()),
-		recorder:  mgr.GetEventRecorderFor("ibm-monitoring-grafana"),
+		client:            mgr.GetClient(),
+		scheme:            mgr.GetScheme(),
+		ctx:               ctx,
+		cancel:            cancel,
+		config:            config,
+		kclient:           mgr.GetAPIReader(),
+		secClient:         secv1client.NewForConfigOrDie(mgr.GetConfig()),
+		recorder:          recorder,
+		applier:           applier.New(mgr.GetClient(), mgr.GetScheme(), recorder),
+		backoff:           newBackoff(),
+		hpaGVK:            hpaGVK,
+		tasksDone:         tasksDone,
+		rotations:         newTaskRuns(tasksDone),
+		userSyncs:         newTaskRuns(tasksDone),
+		staleUserCleanups: newTaskRuns(tasksDone),
 	}
 }
 
@@ -75,8 +109,10 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// Watch for changes to primary resource Grafana
-	err = c.Watch(&source.Kind{Type: &v1alpha1.Grafana{}}, &handler.EnqueueRequestForObject{})
+	// Watch for changes to primary resource Grafana. Status updates made by the
+	// operator itself do not change the generation and are filtered out.
+	err = c.Watch(&source.Kind{Type: &v1alpha1.Grafana{}}, &handler.EnqueueRequestForObject{},
+		predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))
 	if err != nil {
 		return err
 	}
@@ -109,6 +145,15 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// The admin, database and SMTP secrets referenced by the spec belong to
+	// the user, they are mapped by name
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(referencedSecretToGrafanas(mgr.GetClient())))
+
+	if err != nil {
+		return err
+	}
+
 	err = c.Watch(&source.Kind{Type: &ingressv1.Ingress{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
 		OwnerType:    &v1alpha1.Grafana{},
@@ -118,6 +163,50 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Resizes of the grafana storage are reported in status
+	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &v1alpha1.Grafana{},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &policyv1.PodDisruptionBudget{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &v1alpha1.Grafana{},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// The HPA is watched in the version the cluster serves. The VPA is not
+	// watched, its CRD may be missing from the cluster.
+	if reconciler, ok := r.(*ReconcileGrafana); ok && !reconciler.hpaGVK.Empty() {
+		hpa := &unstructured.Unstructured{}
+		hpa.SetGroupVersionKind(reconciler.hpaGVK)
+		err = c.Watch(&source.Kind{Type: hpa}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &v1alpha1.Grafana{},
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// The user syncs and the stale user cleanups run in the background and
+	// reconcile their CR once done
+	if reconciler, ok := r.(*ReconcileGrafana); ok {
+		err = c.Watch(&source.Channel{Source: reconciler.tasksDone}, &handler.EnqueueRequestForObject{})
+
+		if err != nil {
+			return err
+		}
+	}
+
 	err = c.Watch(&source.Kind{Type: &dbv1.MonitoringDashboard{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
 		OwnerType:    &v1alpha1.Grafana{},
@@ -127,9 +216,113 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch the configmaps rendered by ReconcileConfigMaps so manual edits are reverted
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &v1alpha1.Grafana{},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Certificates created before they had an owner are mapped by name
+	err = c.Watch(&source.Kind{Type: &cert.Certificate{}},
+		handler.EnqueueRequestsFromMapFunc(certificateToGrafanas(mgr.GetClient())))
+
+	if err != nil {
+		return err
+	}
+
+	// The cluster monitoring config lives outside of the watched namespace,
+	// so it gets its own cache restricted to that single configmap.
+	monitoringCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: utils.ClusterMonitoringConfigNamespace,
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {
+				Field: fields.OneTermEqualSelector("metadata.name", utils.ClusterMonitoringConfigName),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err = mgr.Add(monitoringCache); err != nil {
+		return err
+	}
+	err = c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, monitoringCache),
+		handler.EnqueueRequestsFromMapFunc(allGrafanas(mgr.GetClient())))
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// certificateToGrafanas enqueues the Grafana CRs serving with the certificate.
+func certificateToGrafanas(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		grafanas := &v1alpha1.GrafanaList{}
+		if err := c.List(context.TODO(), grafanas, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.Error(err, "Fail to list grafana resources for certificate "+obj.GetName())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, cr := range grafanas.Items {
+			if utils.CertSecretName(&cr) == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&cr),
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// referencedSecretToGrafanas enqueues the Grafana CRs whose spec references the secret.
+func referencedSecretToGrafanas(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		grafanas := &v1alpha1.GrafanaList{}
+		if err := c.List(context.TODO(), grafanas, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.Error(err, "Fail to list grafana resources for secret "+obj.GetName())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, cr := range grafanas.Items {
+			for _, name := range utils.ReferencedSecretNames(&cr) {
+				if name == obj.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: client.ObjectKeyFromObject(&cr),
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
+
+// allGrafanas enqueues every Grafana CR, for cluster wide settings.
+func allGrafanas(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		grafanas := &v1alpha1.GrafanaList{}
+		if err := c.List(context.TODO(), grafanas); err != nil {
+			log.Error(err, "Fail to list grafana resources for "+obj.GetName())
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, cr := range grafanas.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&cr),
+			})
+		}
+		return requests
+	}
+}
+
 // blank assignment to verify that ReconcileGrafana implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ReconcileGrafana{}
 
@@ -140,21 +333,36 @@ type ReconcileGrafana struct {
 	client client.Client
 	scheme *runtime.Scheme
 	ctx    context.Context
+	// cancel stops ctx when the manager stops
+	cancel context.CancelFunc
 	config *config.ControllerConfig
 	// This client reads objects from apiserver directly
 	kclient client.Reader
 	// This client is for SCC creation
 	secClient secv1client.Interface
 	recorder  record.EventRecorder
+	// applier creates or patches owned objects only when they changed
+	applier *applier.Applier
+	// backoff computes the retry delay after failed reconciles
+	backoff *backoff
+	// hpaGVK is the HorizontalPodAutoscaler version served by the cluster,
+	// empty when none is supported
+	hpaGVK schema.GroupVersionKind
+	// tasksDone receives the CRs whose background task finished
+	tasksDone chan event.GenericEvent
+	// rotations records the admin credential rotations, they run in the reconcile
+	rotations *taskRuns
+	// userSyncs runs the IAM user syncs in the background
+	userSyncs *taskRuns
+	// staleUserCleanups runs the stale user cleanups in the background
+	staleUserCleanups *taskRuns
 }
 
 // Reconcile reads that state of the cluster for a Grafana object and makes changes based on the state read
 // and what is in the Grafana.Spec
-// TODO(user): Modify this Reconcile function to implement your Controller logic.  This example creates
-// a Pod as an example
 // Note:
-// The Controller will requeue the Request to be processed again if the returned error is non-nil or
-// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+// Steady state relies on the watches set up in add. The request is only requeued after a failure,
+// with a delay that grows with the consecutive failures, or after the optional resync period.
 func (r *ReconcileGrafana) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling Grafana")
@@ -165,25 +373,41 @@ func (r *ReconcileGrafana) Reconcile(context context.Context, request reconcile.
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// The finalizer already cleaned up everything created for it.
 			// Return and don't requeue
 			reqLogger.Info("Grafana resource not found, could have been deleted.")
+			r.backoff.reset(request.NamespacedName)
+			r.rotations.forget(request.NamespacedName)
+			r.userSyncs.forget(request.NamespacedName)
+			r.staleUserCleanups.forget(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
-		return reconcile.Result{Requeue: true, RequeueAfter: utils.RequeueDelay}, err
+		return reconcile.Result{}, err
 	}
 
 	//reconcile all the resources
 	cr := instance.DeepCopy()
 
+	if cr.GetDeletionTimestamp() != nil {
+		return finalizeGrafana(r, cr, instance)
+	}
+	if err := ensureFinalizer(r, cr); err != nil {
+		reqLogger.Error(err, "Fail to add finalizer to grafana.")
+		return handleError(r, cr, instance, err)
+	}
+	if err := checkSpec(cr); err != nil {
+		reqLogger.Info("Refuse to reconcile grafana: " + err.Error())
+		return handleError(r, cr, instance, err)
+	}
+
 	log.Info("Start to reconcile grafana resource.")
 	err = reconcileGrafana(r, cr)
 
 	if err != nil {
 		reqLogger.Error(err, "Fail to reconcile grafana.")
-		return handleError(r, cr, err)
+		return handleError(r, cr, instance, err)
 	}
 
-	return handleSucess(r, cr)
+	return handleSucess(r, cr, instance)
 }
@@ -0,0 +1,108 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestReferencedSecretToGrafanas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	grafana := func(namespace, name string, spec v1alpha1.GrafanaSpec) *v1alpha1.Grafana {
+		return &v1alpha1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}
+	}
+	ref := func(name string) *corev1.LocalObjectReference {
+		return &corev1.LocalObjectReference{Name: name}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		grafana("monitoring", "database", v1alpha1.GrafanaSpec{
+			Database: &v1alpha1.GrafanaDatabase{Type: v1alpha1.DatabasePostgres, Host: "db", SecretRef: ref("db")},
+		}),
+		grafana("monitoring", "smtp", v1alpha1.GrafanaSpec{
+			SMTP: &v1alpha1.GrafanaSMTP{Host: "smtp", SecretRef: ref("smtp"), CertSecretRef: ref("db")},
+		}),
+		grafana("monitoring", "admin", v1alpha1.GrafanaSpec{AdminSecretRef: ref("admin")}),
+		// The secret of a sqlite database is not read
+		grafana("monitoring", "sqlite", v1alpha1.GrafanaSpec{
+			Database: &v1alpha1.GrafanaDatabase{Type: v1alpha1.DatabaseSQLite, SecretRef: ref("db")},
+		}),
+		grafana("other", "database", v1alpha1.GrafanaSpec{
+			Database: &v1alpha1.GrafanaDatabase{Type: v1alpha1.DatabaseMySQL, Host: "db", SecretRef: ref("db")},
+		}),
+	).Build()
+
+	tests := []struct {
+		secret string
+		want   []string
+	}{
+		{"db", []string{"database", "smtp"}},
+		{"smtp", []string{"smtp"}},
+		{"admin", []string{"admin"}},
+		{"unknown", []string{}},
+	}
+	mapFunc := referencedSecretToGrafanas(c)
+	for _, tt := range tests {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tt.secret, Namespace: "monitoring"}}
+		got := []string{}
+		for _, request := range mapFunc(secret) {
+			if request.Namespace != "monitoring" {
+				t.Errorf("secret %s enqueued %s of another namespace", tt.secret, request)
+			}
+			got = append(got, request.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("secret %s enqueued %v, want %v", tt.secret, got, tt.want)
+		}
+	}
+}
+
+func TestSecretDataHash(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", ResourceVersion: "1"},
+		Data:       map[string][]byte{"username": []byte("grafana"), "password": []byte("secret")},
+	}
+	hash := secretDataHash(secret)
+
+	relabeled := secret.DeepCopy()
+	relabeled.ResourceVersion = "2"
+	relabeled.Labels = map[string]string{"team": "ops"}
+	if got := secretDataHash(relabeled); got != hash {
+		t.Errorf("got hash %s after a metadata change, want %s", got, hash)
+	}
+
+	changed := secret.DeepCopy()
+	changed.Data["password"] = []byte("rotated")
+	if got := secretDataHash(changed); got == hash {
+		t.Errorf("got the same hash after a password change")
+	}
+}
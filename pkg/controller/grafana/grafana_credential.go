@@ -0,0 +1,237 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+	grafanaapi "github.com/IBM/ibm-monitoring-grafana-operator/pkg/grafana"
+)
+
+// pendingPasswordKey holds the next admin password while it is being rotated,
+// so that a rotation interrupted half way can be completed.
+const pendingPasswordKey = "pending-password"
+
+// grafanaClient returns a client of a ready grafana pod of cr. The pod is
+// called directly, the router in front of it only accepts IAM users.
+func grafanaClient(r *ReconcileGrafana, cr *v1alpha1.Grafana, user, password string) (*grafanaapi.Client, error) {
+	pods := &corev1.PodList{}
+	err := r.client.List(r.ctx, pods, client.InNamespace(cr.Namespace), client.MatchingLabels(utils.InstanceSelector(cr)))
+	if err != nil {
+		return nil, err
+	}
+	var podIP string
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.PodIP != "" && podReady(&pod) {
+			podIP = pod.Status.PodIP
+			break
+		}
+	}
+	if podIP == "" {
+		return nil, fmt.Errorf("no grafana pod of %s is ready", cr.Name)
+	}
+
+	certs := &corev1.Secret{}
+	key := client.ObjectKey{Name: utils.CertSecretName(cr), Namespace: cr.Namespace}
+	if err := r.client.Get(r.ctx, key, certs); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := grafanaapi.TLSConfig(certs.Data["ca.crt"], certs.Data["tls.crt"], certs.Data["tls.key"], utils.ServiceName(cr))
+	if err != nil {
+		return nil, err
+	}
+	url := "https://" + net.JoinHostPort(podIP, strconv.Itoa(int(utils.ClusterPort(cr))))
+	return grafanaapi.New(url, user, password, tlsConfig), nil
+}
+
+// adminClient returns a client of a ready grafana pod of cr, authenticated
+// as the grafana admin, and the login of the admin.
+func adminClient(r *ReconcileGrafana, cr *v1alpha1.Grafana) (*grafanaapi.Client, string, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(r.ctx, utils.GrafanaSecretSelector(cr), secret); err != nil {
+		return nil, "", err
+	}
+	user := string(secret.Data[utils.GrafanaAdminUserEnvVar])
+	password := string(secret.Data[utils.GrafanaAdminPasswordEnvVar])
+	if user == "" || password == "" {
+		return nil, "", fmt.Errorf("admin secret %s has no %s or %s key", secret.Name,
+			utils.GrafanaAdminUserEnvVar, utils.GrafanaAdminPasswordEnvVar)
+	}
+	gc, err := grafanaClient(r, cr, user, password)
+	return gc, user, err
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// rotationRequested is true when the rotation annotation has a value which was not handled yet
+func rotationRequested(cr *v1alpha1.Grafana) bool {
+	trigger := cr.Annotations[v1alpha1.RotateAdminCredentialAnnotation]
+	if trigger == "" {
+		return false
+	}
+	return cr.Status.AdminCredential == nil || cr.Status.AdminCredential.LastRotationTrigger != trigger
+}
+
+// untilScheduledRotation returns how long until the next scheduled rotation,
+// or a negative duration when no rotation is scheduled.
+func untilScheduledRotation(cr *v1alpha1.Grafana) time.Duration {
+	policy := cr.Spec.AdminCredentialRotation
+	if policy == nil || policy.Interval == nil || policy.Interval.Duration <= 0 {
+		return -1
+	}
+	if cr.Status.AdminCredential == nil || cr.Status.AdminCredential.LastRotationTime == nil {
+		return 0
+	}
+	next := cr.Status.AdminCredential.LastRotationTime.Add(policy.Interval.Duration)
+	if until := time.Until(next); until > 0 {
+		return until
+	}
+	return 0
+}
+
+// untilRotation returns how long until the next requested or scheduled
+// rotation, or a negative duration when none is. The password of
+// spec.adminSecretRef is never rotated. A failed rotation is retried after
+// ConfigErrorRequeueDelay.
+func untilRotation(r *ReconcileGrafana, cr *v1alpha1.Grafana) time.Duration {
+	if cr.Spec.AdminSecretRef != nil && cr.Spec.AdminSecretRef.Name != "" {
+		return -1
+	}
+	until := untilScheduledRotation(cr)
+	if rotationRequested(cr) {
+		until = 0
+	}
+	if until != 0 {
+		return until
+	}
+	if retry := r.rotations.retryAfter(client.ObjectKeyFromObject(cr), utils.ConfigErrorRequeueDelay); retry > 0 {
+		return retry
+	}
+	return 0
+}
+
+// rotateAdminCredential changes the generated admin password in grafana,
+// then in the secret. The config hash on the pod template covers the
+// credential and rolls the containers reading it. Grafana itself keeps its
+// state in its database.
+func rotateAdminCredential(r *ReconcileGrafana, cr *v1alpha1.Grafana, secret *corev1.Secret) (*corev1.Secret, error) {
+	pending := string(secret.Data[pendingPasswordKey])
+	if pending == "" {
+		password, err := utils.GeneratePassword()
+		if err != nil {
+			return nil, err
+		}
+		updated := secret.DeepCopy()
+		updated.Data[pendingPasswordKey] = []byte(password)
+		if err := r.client.Patch(r.ctx, updated, client.MergeFrom(secret)); err != nil {
+			return nil, err
+		}
+		secret, pending = updated, password
+	}
+
+	user := string(secret.Data[utils.GrafanaAdminUserEnvVar])
+	current := string(secret.Data[utils.GrafanaAdminPasswordEnvVar])
+	gc, err := grafanaClient(r, cr, user, current)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := gc.LookupUser(r.ctx, user)
+	switch {
+	case grafanaapi.IsStatus(err, http.StatusUnauthorized):
+		// An earlier attempt changed the password but not the secret
+		gc, err = grafanaClient(r, cr, user, pending)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := gc.LookupUser(r.ctx, user); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := gc.SetUserPassword(r.ctx, admin.ID, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := secret.DeepCopy()
+	updated.Data[utils.GrafanaAdminPasswordEnvVar] = []byte(pending)
+	delete(updated.Data, pendingPasswordKey)
+	if err := r.client.Patch(r.ctx, updated, client.MergeFrom(secret)); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// reconcileRotation rotates the generated admin password when it is due and
+// records the result in status. A failed rotation does not block the reconcile,
+// the current password keeps working and the rotation is retried after
+// ConfigErrorRequeueDelay.
+func reconcileRotation(r *ReconcileGrafana, cr *v1alpha1.Grafana, secret *corev1.Secret) *corev1.Secret {
+	if cr.Status.AdminCredential == nil {
+		cr.Status.AdminCredential = &v1alpha1.AdminCredentialStatus{}
+	}
+	status := cr.Status.AdminCredential
+	if status.LastRotationTime == nil {
+		// The schedule starts when the password is generated
+		created := secret.CreationTimestamp
+		if created.IsZero() {
+			created = metav1.Now()
+		}
+		status.LastRotationTime = &created
+	}
+	if untilRotation(r, cr) != 0 {
+		return secret
+	}
+
+	log.Info("Rotate the admin password of grafana " + cr.Name)
+	// The rotation runs in the reconcile, its attempts are only recorded to
+	// back off after a failure
+	key := client.ObjectKeyFromObject(cr)
+	r.rotations.start(key, cr.Annotations[v1alpha1.RotateAdminCredentialAnnotation])
+	rotated, err := rotateAdminCredential(r, cr, secret)
+	r.rotations.finish(key, nil, err)
+	if err != nil {
+		log.Error(err, "Fail to rotate the admin password of grafana "+cr.Name)
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "AdminCredentialRotationFailed", "Fail to rotate the admin password: %v", err)
+		setCondition(cr, v1alpha1.ConditionAdminCredentialRotated, metav1.ConditionFalse, "RotationFailed", err.Error())
+		return secret
+	}
+	now := metav1.Now()
+	status.LastRotationTime = &now
+	status.LastRotationTrigger = cr.Annotations[v1alpha1.RotateAdminCredentialAnnotation]
+	r.recorder.Event(cr, corev1.EventTypeNormal, "AdminCredentialRotated", "The admin password is rotated")
+	setCondition(cr, v1alpha1.ConditionAdminCredentialRotated, metav1.ConditionTrue, "Rotated",
+		"the admin password was rotated at "+now.UTC().Format(time.RFC3339))
+	return rotated
+}
@@ -0,0 +1,104 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+func TestUntilRotation(t *testing.T) {
+	day := &metav1.Duration{Duration: time.Hour * 24}
+	lastRotation := metav1.NewTime(time.Now().Add(-time.Hour))
+	rotated := &v1alpha1.AdminCredentialStatus{LastRotationTime: &lastRotation, LastRotationTrigger: "1"}
+	tests := []struct {
+		name        string
+		spec        v1alpha1.GrafanaSpec
+		annotations map[string]string
+		status      *v1alpha1.AdminCredentialStatus
+		failed      bool
+		// want is rounded to the minute, -1 when no rotation is due
+		want time.Duration
+	}{
+		{
+			name: "no rotation",
+			want: -1,
+		},
+		{
+			name:   "scheduled",
+			spec:   v1alpha1.GrafanaSpec{AdminCredentialRotation: &v1alpha1.AdminCredentialRotation{Interval: day}},
+			status: rotated,
+			want:   time.Hour * 23,
+		},
+		{
+			name:        "requested",
+			annotations: map[string]string{v1alpha1.RotateAdminCredentialAnnotation: "2"},
+			status:      rotated,
+			want:        0,
+		},
+		{
+			name:        "requested again after a failure",
+			annotations: map[string]string{v1alpha1.RotateAdminCredentialAnnotation: "2"},
+			status:      rotated,
+			failed:      true,
+			want:        utils.ConfigErrorRequeueDelay,
+		},
+		{
+			name:   "due again after a failure",
+			spec:   v1alpha1.GrafanaSpec{AdminCredentialRotation: &v1alpha1.AdminCredentialRotation{Interval: day}},
+			failed: true,
+			want:   utils.ConfigErrorRequeueDelay,
+		},
+		{
+			name: "password of the admin secret ref",
+			spec: v1alpha1.GrafanaSpec{
+				AdminSecretRef:          &corev1.LocalObjectReference{Name: "admin"},
+				AdminCredentialRotation: &v1alpha1.AdminCredentialRotation{Interval: day},
+			},
+			want: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReconcileGrafana{rotations: newTaskRuns(nil)}
+			cr := &v1alpha1.Grafana{
+				ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring", Annotations: tt.annotations},
+				Spec:       tt.spec,
+				Status:     v1alpha1.GrafanaStatus{AdminCredential: tt.status},
+			}
+			if tt.failed {
+				key := client.ObjectKeyFromObject(cr)
+				r.rotations.start(key, "")
+				r.rotations.finish(key, nil, fmt.Errorf("grafana is down"))
+			}
+			got := untilRotation(r, cr)
+			if got > 0 {
+				got = got.Round(time.Minute)
+			}
+			if got != tt.want {
+				t.Errorf("got %v until the rotation, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// checkDatabase makes sure the external database can be used before grafana
+// is configured with it: its credentials secret must be complete and its
+// server must accept connections. Grafana does not start without its database.
+func checkDatabase(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !utils.ExternalDatabase(cr) {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionDatabaseReachable)
+		return nil
+	}
+
+	if ref := cr.Spec.Database.SecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(r.ctx, client.ObjectKey{Name: ref.Name, Namespace: cr.Namespace}, secret); err != nil {
+			setCondition(cr, v1alpha1.ConditionDatabaseReachable, metav1.ConditionFalse, "SecretNotFound",
+				fmt.Sprintf("fail to get database secret %s: %v", ref.Name, err))
+			return err
+		}
+		for _, key := range []string{utils.DatabaseUserKey, utils.DatabasePasswordKey} {
+			if _, ok := secret.Data[key]; !ok {
+				err := fmt.Errorf("database secret %s has no %s key", ref.Name, key)
+				setCondition(cr, v1alpha1.ConditionDatabaseReachable, metav1.ConditionFalse, "InvalidSecret", err.Error())
+				return err
+			}
+		}
+	}
+
+	address := utils.DatabaseAddress(cr)
+	conn, err := net.DialTimeout("tcp", address, utils.DatabaseDialTimeout)
+	if err != nil {
+		err = fmt.Errorf("%s database %s is not reachable: %v", utils.DatabaseType(cr), address, err)
+		setCondition(cr, v1alpha1.ConditionDatabaseReachable, metav1.ConditionFalse, "Unreachable", err.Error())
+		return err
+	}
+	conn.Close()
+	setCondition(cr, v1alpha1.ConditionDatabaseReachable, metav1.ConditionTrue, "Reachable",
+		fmt.Sprintf("%s database %s accepts connections", utils.DatabaseType(cr), address))
+	return nil
+}
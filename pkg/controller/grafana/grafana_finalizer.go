@@ -0,0 +1,219 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+
+	dbv1 "github.ibm.com/IBMPrivateCloud/grafana-dashboard-crd/pkg/apis/monitoringcontroller/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/dashboards"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// GrafanaFinalizer blocks the deletion of a Grafana CR until all the
+// resources created for it are deleted.
+const GrafanaFinalizer = "operator.ibm.com/grafana-cleanup"
+
+// ensureFinalizer adds the finalizer to a Grafana CR which is not being deleted.
+func ensureFinalizer(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if controllerutil.ContainsFinalizer(cr, GrafanaFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(cr, GrafanaFinalizer)
+	return r.client.Update(r.ctx, cr)
+}
+
+// ownedResources lists every resource the operator creates for a Grafana CR.
+// Owner references already cover most of them, but not the dashboards
+// created in another namespace. The default dashboards are left out while
+// another instance still uses them.
+func ownedResources(r *ReconcileGrafana, cr *v1alpha1.Grafana, withDashboards bool) []client.Object {
+	resources := []client.Object{
+		utils.GrafanaDeployment(cr),
+		utils.GrafanaService(cr),
+		utils.GrafanaIngress(cr),
+		utils.GrafanaPodDisruptionBudget(cr),
+		utils.GetCertificate(utils.CertSecretName(cr), cr),
+	}
+	// A secret provided through spec.adminSecretRef belongs to the user
+	if cr.Spec.AdminSecretRef == nil || cr.Spec.AdminSecretRef.Name == "" {
+		resources = append(resources, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GeneratedAdminSecretName(cr),
+			Namespace: cr.Namespace,
+		}})
+	}
+	if utils.ManagedPersistentVolumeClaim(cr) && !utils.RetainPersistentVolumeClaim(cr) {
+		resources = append(resources, utils.GrafanaPersistentVolumeClaim(cr))
+	}
+	if secret, err := utils.DSProxyConfigSecret(cr, nil); err == nil {
+		resources = append(resources, secret)
+	}
+	if !r.hpaGVK.Empty() {
+		if hpa, err := utils.GrafanaHorizontalPodAutoscaler(cr, r.hpaGVK); err == nil {
+			resources = append(resources, hpa)
+		}
+	}
+	if utils.VerticalAutoscalingEnabled(cr) {
+		resources = append(resources, utils.GrafanaVerticalPodAutoscaler(cr))
+	}
+	for _, cm := range utils.ReconcileConfigMaps(cr) {
+		resources = append(resources, cm)
+	}
+	if cm, err := utils.GrafanaOrgsConfigMap(cr, nil); err == nil {
+		resources = append(resources, cm)
+	}
+	if !withDashboards {
+		return resources
+	}
+	namespace := dashboardNamespace(cr)
+	for name := range dashboards.DefaultDBsStatus {
+		resources = append(resources, dashboards.CreateDashboard(namespace, name, false))
+	}
+	return resources
+}
+
+// createdFor is true when the finalizer may delete obj: cr controls it, or it
+// is a dashboard in another namespace, where it can not have an owner. The
+// objects of the same name created by users, e.g. the certificate named by
+// spec.tlsSecretName, are left alone.
+func createdFor(cr *v1alpha1.Grafana, obj client.Object) bool {
+	if metav1.IsControlledBy(obj, cr) {
+		return true
+	}
+	_, dashboard := obj.(*dbv1.MonitoringDashboard)
+	return dashboard && obj.GetNamespace() != cr.Namespace && metav1.GetControllerOf(obj) == nil
+}
+
+// finalizeGrafana deletes all the resources of a Grafana CR being deleted and
+// removes the finalizer once none of them is left.
+func finalizeGrafana(r *ReconcileGrafana, cr *v1alpha1.Grafana, original *v1alpha1.Grafana) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(cr, GrafanaFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	log.Info("Start to clean up the resources of grafana " + cr.Name)
+	shared, err := dashboardsShared(r, cr)
+	if err != nil {
+		return handleError(r, cr, original, err)
+	}
+	if err := releasePersistentVolumeClaim(r, cr); err != nil {
+		return handleError(r, cr, original, err)
+	}
+	resources := ownedResources(r, cr, !shared)
+	remaining := 0
+	for _, obj := range resources {
+		// Read from the apiserver, dashboards may live outside of the watched namespace
+		current := obj.DeepCopyObject().(client.Object)
+		err := r.kclient.Get(r.ctx, client.ObjectKeyFromObject(obj), current)
+		// The VPA CRD may be missing from the cluster
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return handleError(r, cr, original, err)
+		}
+		if !createdFor(cr, current) {
+			continue
+		}
+		remaining++
+		if current.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := r.client.Delete(r.ctx, current); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, fmt.Sprintf("Fail to delete %s/%s", current.GetNamespace(), current.GetName()))
+			return handleError(r, cr, original, err)
+		}
+	}
+
+	if remaining > 0 {
+		message := fmt.Sprintf("waiting for %d of %d resources to be deleted", remaining, len(resources))
+		cr.Status.Phase = v1alpha1.PhaseTerminating
+		cr.Status.Message = message
+		setCondition(cr, v1alpha1.ConditionTerminating, metav1.ConditionTrue, "DeletingResources", message)
+		if err := updateStatus(r, cr, original); err != nil && !errors.IsConflict(err) {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: utils.MinRequeueDelay}, nil
+	}
+
+	log.Info("All the resources of grafana " + cr.Name + " are deleted")
+	deleteStaleUserMetrics(cr)
+	controllerutil.RemoveFinalizer(cr, GrafanaFinalizer)
+	if err := r.client.Update(r.ctx, cr); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	r.backoff.reset(client.ObjectKeyFromObject(cr))
+	return reconcile.Result{}, nil
+}
+
+// dashboardsShared is true when another Grafana CR creates its default
+// dashboards in the same namespace as cr.
+func dashboardsShared(r *ReconcileGrafana, cr *v1alpha1.Grafana) (bool, error) {
+	grafanas := &v1alpha1.GrafanaList{}
+	if err := r.client.List(r.ctx, grafanas); err != nil {
+		return false, err
+	}
+	for i := range grafanas.Items {
+		other := &grafanas.Items[i]
+		if other.UID == cr.UID || other.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if dashboardNamespace(other) == dashboardNamespace(cr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// releasePersistentVolumeClaim drops the owner reference of a retained claim,
+// so that the garbage collector keeps it once the CR is gone.
+func releasePersistentVolumeClaim(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !utils.ManagedPersistentVolumeClaim(cr) || !utils.RetainPersistentVolumeClaim(cr) {
+		return nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.client.Get(r.ctx, client.ObjectKey{Name: utils.PersistentVolumeClaimName(cr), Namespace: cr.Namespace}, pvc)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	owners := []metav1.OwnerReference{}
+	for _, owner := range pvc.OwnerReferences {
+		if owner.UID != cr.UID {
+			owners = append(owners, owner)
+		}
+	}
+	if len(owners) == len(pvc.OwnerReferences) {
+		return nil
+	}
+	released := pvc.DeepCopy()
+	released.OwnerReferences = owners
+	if err := r.client.Patch(r.ctx, released, client.MergeFrom(pvc)); err != nil {
+		return err
+	}
+	log.Info("Persistent volume claim " + pvc.Name + " is retained")
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "PersistentVolumeClaimRetained",
+		"persistent volume claim %s is kept after the grafana resource is deleted", pvc.Name)
+	return nil
+}
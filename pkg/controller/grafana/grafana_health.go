@@ -0,0 +1,109 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+	grafanaapi "github.com/IBM/ibm-monitoring-grafana-operator/pkg/grafana"
+)
+
+// healthFailureThreshold is the number of failed health checks in a row
+// after which grafana is reported degraded.
+const healthFailureThreshold = 3
+
+// checkGrafanaHealth calls the grafana health endpoint through the service,
+// as the clients of grafana do, and records the answer in status.
+func checkGrafanaHealth(r *ReconcileGrafana, cr *v1alpha1.Grafana) {
+	if !isAvailable(cr) {
+		return
+	}
+	if cr.Status.Health == nil {
+		cr.Status.Health = &v1alpha1.GrafanaHealthStatus{}
+	}
+	status := cr.Status.Health
+
+	health, err := serviceHealth(r, cr)
+	if err == nil && health.Database != grafanaapi.DatabaseOK {
+		err = fmt.Errorf("grafana reports its database %s", health.Database)
+	}
+	if err != nil {
+		log.Error(err, "Fail to check the health of grafana "+cr.Name)
+		status.ConsecutiveFailures++
+		if grafanaapi.IsStatus(err, http.StatusServiceUnavailable) {
+			status.Database = "failing"
+		}
+		if status.ConsecutiveFailures < healthFailureThreshold {
+			return
+		}
+		message := fmt.Sprintf("%d health checks failed in a row: %v", status.ConsecutiveFailures, err)
+		if status.ConsecutiveFailures == healthFailureThreshold {
+			r.recorder.Event(cr, corev1.EventTypeWarning, "GrafanaUnhealthy", message)
+		}
+		setCondition(cr, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "HealthCheckFailed", message)
+		return
+	}
+
+	if status.ConsecutiveFailures >= healthFailureThreshold {
+		r.recorder.Event(cr, corev1.EventTypeNormal, "GrafanaHealthy", "grafana answers its health checks again")
+	}
+	now := metav1.Now()
+	status.ConsecutiveFailures = 0
+	status.Database = health.Database
+	status.Version = health.Version
+	status.Commit = health.Commit
+	status.LastCheckTime = &now
+}
+
+// healthFailing is true once the health checks failed healthFailureThreshold times in a row
+func healthFailing(cr *v1alpha1.Grafana) bool {
+	return cr.Status.Health != nil && cr.Status.Health.ConsecutiveFailures >= healthFailureThreshold
+}
+
+// untilHealthCheck returns how long until the next health check, sooner
+// while the checks fail, or a negative duration while grafana is not available.
+func untilHealthCheck(cr *v1alpha1.Grafana) time.Duration {
+	if !isAvailable(cr) {
+		return -1
+	}
+	if cr.Status.Health != nil && cr.Status.Health.ConsecutiveFailures > 0 {
+		return utils.RequeueDelay
+	}
+	return utils.HealthCheckInterval
+}
+
+// serviceHealth calls the health endpoint of the grafana service with the
+// grafana certificate, which the router requires as client certificate.
+func serviceHealth(r *ReconcileGrafana, cr *v1alpha1.Grafana) (*grafanaapi.Health, error) {
+	certs := &corev1.Secret{}
+	key := client.ObjectKey{Name: utils.CertSecretName(cr), Namespace: cr.Namespace}
+	if err := r.client.Get(r.ctx, key, certs); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := grafanaapi.TLSConfig(certs.Data["ca.crt"], certs.Data["tls.crt"], certs.Data["tls.key"], "")
+	if err != nil {
+		return nil, err
+	}
+	return grafanaapi.New(utils.ServiceURL(cr), "", "", tlsConfig).Health(r.ctx)
+}
@@ -0,0 +1,73 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// reconcilePluginStatus reports the plugins grafana loaded. The install
+// container fails the pod when a plugin can not be installed, a plugin
+// missing here was installed but refused by grafana, e.g. unsigned.
+func reconcilePluginStatus(r *ReconcileGrafana, cr *v1alpha1.Grafana) {
+	if !utils.PluginsEnabled(cr) {
+		cr.Status.Plugins = nil
+		return
+	}
+	if !isAvailable(cr) {
+		return
+	}
+	gc, _, err := adminClient(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to get the plugins of grafana "+cr.Name)
+		return
+	}
+	plugins, err := gc.Plugins(r.ctx)
+	if err != nil {
+		log.Error(err, "Fail to get the plugins of grafana "+cr.Name)
+		return
+	}
+
+	installed := []v1alpha1.InstalledPlugin{}
+	loaded := map[string]bool{}
+	for _, plugin := range plugins {
+		installed = append(installed, v1alpha1.InstalledPlugin{
+			ID:        plugin.ID,
+			Version:   plugin.Info.Version,
+			Signature: plugin.Signature,
+		})
+		loaded[plugin.ID] = true
+	}
+	sort.Slice(installed, func(i, j int) bool { return installed[i].ID < installed[j].ID })
+
+	missing := []string{}
+	for _, plugin := range cr.Spec.Plugins {
+		if !loaded[plugin.Name] {
+			missing = append(missing, plugin.Name)
+		}
+	}
+	if len(missing) > 0 {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "PluginNotLoaded",
+			"Grafana did not load the plugins %s", strings.Join(missing, ", "))
+	}
+	cr.Status.Plugins = installed
+}
@@ -16,25 +16,33 @@
 package grafana
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"time"
 
 	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	ingressv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
 
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/applier"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/config"
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/dashboards"
 
 	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
 )
 
-var IsGrafanaRunning bool = false
-
 func reconcileGrafana(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 
 	err := checkApplicationMonitoring(r, cr)
@@ -42,11 +50,33 @@ func reconcileGrafana(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 		log.Error(err, "Fail to check OCP application monitoring status")
 		return err
 	}
-	err = reconcileAllConfigMaps(r, cr)
+	// grafana.ini points to the database, check it before rolling it out
+	err = checkDatabase(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to check grafana database")
+		return err
+	}
+	err = checkSMTP(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to check the SMTP server")
+		return err
+	}
+	credential, err := reconcileGrafanaSecret(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to reconcile grafana secret.")
+		return err
+	}
+
+	configHash, err := reconcileAllConfigMaps(r, cr, credential)
 	if err != nil {
 		log.Error(err, "Fail to reconcile all the confimags.")
 		return err
 	}
+	// The user sync applies the role mapping, it is not used in the other login modes
+	cr.Status.RoleMapping = nil
+	if utils.RouterEnabled(cr) {
+		cr.Status.RoleMapping = utils.RoleMapping(cr)
+	}
 
 	err = reconcileCert(r, cr)
 	if err != nil {
@@ -70,23 +100,49 @@ func reconcileGrafana(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 		log.Error(err, "Fail to reconcile grafana ingress.")
 	}
 
-	err = reconcileGrafanaSecret(r, cr)
+	err = reconcileGrafanaStorage(r, cr)
 	if err != nil {
-		log.Error(err, "Fail to reconcile grafana secret.")
+		log.Error(err, "Fail to reconcile grafana storage.")
+		return err
 	}
 
-	err = reconcileGrafanaDeployment(r, cr)
+	setSecurityProfileCondition(cr)
+	setDeprecatedResourcesCondition(r, cr)
+	err = reconcileGrafanaDeployment(r, cr, configHash)
 	if err != nil {
 		log.Error(err, "Fail to reconcile grafana deployment.")
 		return err
 	}
 
+	err = reconcileAutoscaling(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to reconcile grafana autoscalers.")
+		return err
+	}
+
+	err = reconcileGrafanaPodDisruptionBudget(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to reconcile grafana pod disruption budget.")
+		return err
+	}
+
+	err = migrateLegacyObjects(r, cr)
+	if err != nil {
+		log.Error(err, "Fail to delete the objects of the single instance release.")
+		return err
+	}
+
 	err = reconcileAllDashboards(r, cr)
 	if err != nil {
 		log.Error(err, "Fail to  reconcile grafana dashboards.")
 		return err
 	}
 
+	reconcileUserSync(r, cr)
+	reconcileStaleUsers(r, cr)
+	reconcilePluginStatus(r, cr)
+	checkGrafanaHealth(r, cr)
+
 	err = cleanupCSMonitoring(r, cr)
 	if err != nil {
 		// no need to return error here as its just cleanup and no impact if it fails
@@ -98,265 +154,339 @@ func reconcileGrafana(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 	return nil
 }
 
-func reconcileAllConfigMaps(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+// reconcileAllConfigMaps applies the rendered configmaps and returns a hash of
+// their content, of the admin credential and of the database and SMTP
+// secrets. The hash is set on the pod template, because files mounted with a
+// subPath and environment variables are not refreshed: the pods are rolled
+// when it changes.
+func reconcileAllConfigMaps(r *ReconcileGrafana, cr *v1alpha1.Grafana, credential string) (string, error) {
 	configmaps := utils.ReconcileConfigMaps(cr)
-	selector := func(name string) client.ObjectKey {
-		return client.ObjectKey{
-			Namespace: cr.Namespace,
-			Name:      name,
-		}
-	}
+	sort.Slice(configmaps, func(i, j int) bool { return configmaps[i].Name < configmaps[j].Name })
+	hash := sha256.New()
 
-	create := func(cm *corev1.ConfigMap) error {
-		err := controllerutil.SetControllerReference(cr, cm, r.scheme)
+	log.Info("Start to reconcile all the confimaps")
+	for _, cm := range configmaps {
+		desired := cm
+		_, err := r.applier.Apply(r.ctx, cr, desired, func(obj client.Object) error {
+			current := obj.(*corev1.ConfigMap)
+			current.Labels = desired.Labels
+			current.Data = desired.Data
+			return nil
+		})
 		if err != nil {
-			return err
+			log.Error(err, fmt.Sprintf("Fail to reconcile configmap %s", desired.Name))
+			return "", err
 		}
-		err = r.client.Create(r.ctx, cm)
+		cmHash, err := applier.SemanticHash(desired)
 		if err != nil {
-			return err
+			return "", err
 		}
-		log.Info(fmt.Sprintf("configmap %s is created.", cm.ObjectMeta.Name))
-		return nil
-	}
-
-	update := func(cm *corev1.ConfigMap) error {
-		err := controllerutil.SetControllerReference(cr, cm, r.scheme)
-		if err != nil {
-			return err
-		}
-		err = r.client.Update(r.ctx, cm)
-		if err != nil {
-			return err
+		hash.Write([]byte(desired.Name + "=" + cmHash + "\n"))
+	}
+	// The containers read the admin credential from their environment
+	hash.Write([]byte("credential=" + credential + "\n"))
+	// and the database and SMTP credentials too
+	for _, name := range append(utils.DatabaseSecretNames(cr), utils.SMTPSecretNames(cr)...) {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(r.ctx, client.ObjectKey{Name: name, Namespace: cr.Namespace}, secret); err != nil {
+			return "", err
 		}
-		log.Info(fmt.Sprintf("configmap %s is updated.", cm.ObjectMeta.Name))
-		return nil
+		hash.Write([]byte("secret/" + name + "=" + secretDataHash(secret) + "\n"))
 	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-	log.Info("Start to reconcile all the confimaps")
-	for _, cm := range configmaps {
-		name := cm.ObjectMeta.Name
-		ocm := corev1.ConfigMap{}
-		err := r.client.Get(r.ctx, selector(name), &ocm)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				err = create(cm)
-				if err != nil {
-					log.Error(err, fmt.Sprintf("Fail to create configmap %s", name))
-					return err
-				}
-				log.Info(fmt.Sprintf("configmap %s created.", name))
-			}
-			return err
-		}
+// secretDataHash returns a hash of the data of a secret, its metadata left out
+func secretDataHash(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		sum := sha256.Sum256(secret.Data[key])
+		hash.Write([]byte(key + "=" + hex.EncodeToString(sum[:]) + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
 
-		err = update(cm)
-		if err != nil {
-			log.Error(err, fmt.Sprintf("Fail to update configmap %s", name))
-			return err
-		}
+// dashboardNamespace is the namespace of the main org, where the default dashboards are created.
+func dashboardNamespace(cr *v1alpha1.Grafana) string {
+	if cr.Spec.DashboardsConfig != nil && cr.Spec.DashboardsConfig.MainOrg != "" {
+		return cr.Spec.DashboardsConfig.MainOrg
 	}
-	return nil
+	return cr.Namespace
 }
 
 func reconcileAllDashboards(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 
-	var namespace string = cr.Namespace
+	namespace := dashboardNamespace(cr)
 
 	log.Info("Start to reconcile grafana dashboards")
 
 	// Update the dashboards status
-	if cr.Spec.DashboardsConfig != nil && cr.Spec.DashboardsConfig.MainOrg != "" {
-		namespace = cr.Spec.DashboardsConfig.MainOrg
-	}
-
 	dashboards.ReconcileDashboardsStatus(cr)
 
 	// Reconcile all the dashboards
 	// Could not get the dashboard resource and workaround this.
 	for name, status := range dashboards.DefaultDBsStatus {
 		db := dashboards.CreateDashboard(namespace, name, status)
-		_ = controllerutil.SetControllerReference(cr, db, r.scheme)
+		// Owner references can not cross namespaces, the finalizer deletes those dashboards
+		if namespace == cr.Namespace {
+			if err := controllerutil.SetControllerReference(cr, db, r.scheme); err != nil {
+				return err
+			}
+		}
 		err := r.client.Create(r.ctx, db)
 		if err != nil {
 			if errors.IsAlreadyExists(err) {
 				continue
 			} else {
 				log.Error(err, "fail to create dashboard", name)
+				setCondition(cr, v1alpha1.ConditionDashboardsSynced, metav1.ConditionFalse, "CreateFailed",
+					fmt.Sprintf("fail to create dashboard %s: %v", name, err))
 				return err
 			}
 		}
 	}
+	setCondition(cr, v1alpha1.ConditionDashboardsSynced, metav1.ConditionTrue, "DashboardsCreated",
+		fmt.Sprintf("dashboards are created in namespace %s", namespace))
 	return nil
 }
 
-func reconcileGrafanaSecret(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-
-	selector := utils.GrafanaSecretSelector(cr)
-	secret := utils.CreateGrafanaSecret(cr)
-	err := controllerutil.SetControllerReference(cr, secret, r.scheme)
-	if err != nil {
-		return err
-	}
-	err = r.client.Get(r.ctx, selector, secret)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			err = r.client.Create(r.ctx, secret)
-			if err != nil {
-				return err
-			}
-			return nil
+// reconcileGrafanaSecret makes sure the admin secret exists and returns the
+// credential the containers read, for the config hash. A secret provided
+// through spec.adminSecretRef is only read. Otherwise the operator generates
+// one with a random password, which is never overwritten.
+func reconcileGrafanaSecret(r *ReconcileGrafana, cr *v1alpha1.Grafana) (string, error) {
+
+	if cr.Spec.AdminSecretRef != nil && cr.Spec.AdminSecretRef.Name != "" {
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Name: cr.Spec.AdminSecretRef.Name, Namespace: cr.Namespace}
+		if err := r.client.Get(r.ctx, key, secret); err != nil {
+			log.Error(err, "Fail to get grafana admin secret "+key.Name)
+			return "", err
 		}
-		return err
+		if cr.Spec.AdminCredentialRotation != nil || rotationRequested(cr) {
+			setCondition(cr, v1alpha1.ConditionAdminCredentialRotated, metav1.ConditionFalse, "NotSupported",
+				"the admin password of spec.adminSecretRef is managed by its owner and is not rotated")
+		}
+		return utils.GrafanaCredential(secret)
+	}
+
+	secret, err := utils.CreateGrafanaSecret(cr)
+	if err != nil {
+		return "", err
+	}
+	// Read from the apiserver, a rotation may have just changed the password
+	current := &corev1.Secret{}
+	err = r.kclient.Get(r.ctx, client.ObjectKeyFromObject(secret), current)
+	if err == nil {
+		secret.Data = reconcileRotation(r, cr, current).Data
+		delete(secret.Data, pendingPasswordKey)
+	} else if errors.IsNotFound(err) {
+		// Keep the credentials of the single instance release
+		legacy := &corev1.Secret{}
+		err = r.client.Get(r.ctx, client.ObjectKey{Name: utils.GrafanaAdminSecretName, Namespace: cr.Namespace}, legacy)
+		if err == nil && metav1.IsControlledBy(legacy, cr) && legacy.Name != secret.Name {
+			secret.Data = legacy.Data
+		} else if err != nil && !errors.IsNotFound(err) {
+			return "", err
+		}
+	} else {
+		return "", err
 	}
-	err = r.client.Update(r.ctx, secret)
+
+	// The credentials are only set on creation and by rotations.
+	_, err = r.applier.Apply(r.ctx, cr, secret, func(obj client.Object) error {
+		obj.SetLabels(secret.Labels)
+		return nil
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return utils.GrafanaCredential(secret)
 }
 
-func reconcileGrafanaDeployment(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+func reconcileGrafanaDeployment(r *ReconcileGrafana, cr *v1alpha1.Grafana, configHash string) error {
 
 	selector := utils.GrafanaDeploymentSelector(cr)
 	deployment := &appv1.Deployment{}
 	err := r.client.Get(r.ctx, selector, deployment)
-	if err != nil && errors.IsNotFound(err) {
-		err = createGrafanaDeployment(r, cr)
-		if err != nil {
-			log.Error(err, "Fail to create grafana deployment.")
-			return err
-		}
-		log.Info("Grafana deployment created")
-		return nil
-	}
-	if err != nil {
+	if err != nil && !errors.IsNotFound(err) {
 		log.Error(err, "Fail to get grafana deployment.")
 		return err
 	}
-
-	toUpdate := utils.ReconciledGrafanaDeployment(cr, deployment)
+	if err == nil {
+		// The selector is immutable, a deployment created with another one is
+		// recreated. This happens to the single instance release deployment
+		// when the CR is named ibm-monitoring.
+		desired := utils.GrafanaDeployment(cr)
+		if !equality.Semantic.DeepEqual(deployment.Spec.Selector, desired.Spec.Selector) {
+			log.Info("Recreate grafana deployment " + deployment.Name + " to change its selector")
+			if err := r.client.Delete(r.ctx, deployment); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			setCondition(cr, v1alpha1.ConditionAvailable, metav1.ConditionFalse, "RecreatingDeployment",
+				"grafana deployment is recreated with a new selector")
+			setCondition(cr, v1alpha1.ConditionProgressing, metav1.ConditionTrue, "RecreatingDeployment",
+				"grafana deployment is recreated with a new selector")
+			return nil
+		}
+		setDeploymentConditions(cr, deployment)
+	}
 
 	certmanagerLabel := "certmanager.k8s.io/time-restarted"
-	// Preserve cert-manager added labels in metadata
-	if val, ok := deployment.ObjectMeta.Labels[certmanagerLabel]; ok {
-		toUpdate.ObjectMeta.Labels[certmanagerLabel] = val
-	}
+	desired := utils.GrafanaDeployment(cr)
+	desired.Spec.Template.Annotations[utils.ConfigHashAnnotation] = configHash
+	result, err := r.applier.Apply(r.ctx, cr, desired, func(obj client.Object) error {
+		current := obj.(*appv1.Deployment)
+		toUpdate := utils.ReconciledGrafanaDeployment(cr, current)
+		toUpdate.Spec.Template.Annotations[utils.ConfigHashAnnotation] = configHash
+
+		// Preserve cert-manager added labels in metadata
+		if val, ok := current.ObjectMeta.Labels[certmanagerLabel]; ok {
+			toUpdate.ObjectMeta.Labels[certmanagerLabel] = val
+		}
 
-	// Preserve cert-manager added labels in spec
-	if val, ok := deployment.Spec.Template.ObjectMeta.Labels[certmanagerLabel]; ok {
-		toUpdate.Spec.Template.ObjectMeta.Labels[certmanagerLabel] = val
-	}
-	err = r.client.Update(r.ctx, toUpdate)
+		// Preserve cert-manager added labels in spec
+		if val, ok := current.Spec.Template.ObjectMeta.Labels[certmanagerLabel]; ok {
+			toUpdate.Spec.Template.ObjectMeta.Labels[certmanagerLabel] = val
+		}
+		*current = *toUpdate
+		return nil
+	})
 	if err != nil {
-		log.Error(err, "Fail to update grafana deployment.")
+		log.Error(err, "Fail to reconcile grafana deployment.")
 		return err
 	}
-	log.Info("Grafana deployment updated")
+	if result == applier.OperationResultCreated {
+		setCondition(cr, v1alpha1.ConditionAvailable, metav1.ConditionFalse, "DeploymentCreated",
+			"grafana deployment is created")
+		setCondition(cr, v1alpha1.ConditionProgressing, metav1.ConditionTrue, "DeploymentCreated",
+			"grafana deployment is created")
+	}
 
 	return nil
 }
 
-func createGrafanaDeployment(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-
-	dep := utils.GrafanaDeployment(cr)
-	err := controllerutil.SetControllerReference(cr, dep, r.scheme)
-	if err != nil {
-		return err
+// reconcileGrafanaStorage creates the claim of the grafana storage unless the
+// user provides one. The claim is expanded when the requested size grows,
+// the other fields of a claim can not change once it is created.
+// A claim is not deleted when the persistent volume gets disabled, to keep the data.
+func reconcileGrafanaStorage(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !utils.ManagedPersistentVolumeClaim(cr) {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionStorageReady)
+		return nil
 	}
 
-	err = r.client.Create(r.ctx, dep)
+	desired := utils.GrafanaPersistentVolumeClaim(cr)
+	size := utils.StorageSize(cr)
+	shrink := false
+	_, err := r.applier.Apply(r.ctx, cr, desired, func(obj client.Object) error {
+		current := obj.(*corev1.PersistentVolumeClaim)
+		current.Labels = desired.Labels
+		if current.Spec.Resources.Requests == nil {
+			current.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		requested := current.Spec.Resources.Requests[corev1.ResourceStorage]
+		switch size.Cmp(requested) {
+		case 1:
+			current.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		case -1:
+			shrink = true
+		}
+		return nil
+	})
 	if err != nil {
+		setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionFalse, "ApplyFailed", err.Error())
 		return err
 	}
 
-	return nil
-}
-
-func reconcileGrafanaService(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-
-	selector := utils.GrafanaServiceSelector(cr)
-	svc := &corev1.Service{}
-	err := r.client.Get(r.ctx, selector, svc)
-	if err != nil {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(r.ctx, client.ObjectKeyFromObject(desired), pvc); err != nil {
 		if errors.IsNotFound(err) {
-			err = createGrafanaService(r, cr)
-			if err != nil {
-				return err
-			}
+			setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionFalse, "Pending",
+				"persistent volume claim "+desired.Name+" is created")
 			return nil
 		}
 		return err
 	}
-
-	toUpdate := utils.ReconciledGrafanaService(cr, svc)
-	err = r.client.Update(r.ctx, toUpdate)
-	if err != nil {
-		return err
-	}
+	setStorageCondition(cr, pvc, size, shrink)
 	return nil
 }
 
-func createGrafanaService(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-	svc := utils.GrafanaService(cr)
-	err := controllerutil.SetControllerReference(cr, svc, r.scheme)
-
-	if err != nil {
-		return err
+// reconcileGrafanaPodDisruptionBudget protects highly available grafana from
+// voluntary disruptions. A budget which lets no pod be evicted would block node
+// drains forever, it is not created and the PodDisruptionBudgetReady condition
+// tells why.
+func reconcileGrafanaPodDisruptionBudget(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	pdb := utils.GrafanaPodDisruptionBudget(cr)
+	reason, message := "", ""
+	if utils.MaxReplicas(cr) <= 1 {
+		reason, message = "SingleReplica", "grafana runs a single replica, a PodDisruptionBudget would block node drains"
+	} else {
+		allowed, err := utils.DisruptionsAllowed(cr)
+		if err != nil {
+			return err
+		}
+		if allowed == 0 {
+			reason = "NoDisruptionAllowed"
+			message = fmt.Sprintf("spec.podDisruptionBudget lets no pod be evicted with %d replicas, "+
+				"a PodDisruptionBudget would block node drains", utils.MinReplicas(cr))
+		}
+	}
+	if reason != "" {
+		setCondition(cr, v1alpha1.ConditionPodDisruptionBudgetReady, metav1.ConditionFalse, reason, message)
+		return deleteControlledObject(r, cr, &policyv1.PodDisruptionBudget{ObjectMeta: pdb.ObjectMeta})
 	}
 
-	err = r.client.Create(r.ctx, svc)
+	_, err := r.applier.Apply(r.ctx, cr, pdb, func(obj client.Object) error {
+		current := obj.(*policyv1.PodDisruptionBudget)
+		current.Labels = pdb.Labels
+		current.Spec = pdb.Spec
+		return nil
+	})
 	if err != nil {
+		setCondition(cr, v1alpha1.ConditionPodDisruptionBudgetReady, metav1.ConditionFalse, "ApplyFailed", err.Error())
 		return err
 	}
-
+	setCondition(cr, v1alpha1.ConditionPodDisruptionBudgetReady, metav1.ConditionTrue, "Created",
+		"PodDisruptionBudget "+pdb.Name+" is created")
 	return nil
-
 }
 
-func reconcileGrafanaIngress(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-
-	selector := utils.GrafanaIngressSelector(cr)
-	route := &ingressv1.Ingress{}
-	err := r.client.Get(r.ctx, selector, route)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			err = createGrafanaIngress(r, cr)
-			if err != nil {
-				return err
-			}
-			return nil
-		}
-		return err
-	}
-	toUpdate := utils.ReconciledGrafanaIngress(cr, route)
+func reconcileGrafanaService(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 
-	err = r.client.Update(r.ctx, toUpdate)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err := r.applier.Apply(r.ctx, cr, utils.GrafanaService(cr), func(obj client.Object) error {
+		current := obj.(*corev1.Service)
+		*current = *utils.ReconciledGrafanaService(cr, current)
+		return nil
+	})
+	return err
 }
 
-func createGrafanaIngress(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-	route := utils.GrafanaIngress(cr)
-	err := controllerutil.SetControllerReference(cr, route, r.scheme)
-	if err != nil {
-		return err
+func reconcileGrafanaIngress(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !utils.IngressEnabled(cr) {
+		return deleteControlledObject(r, cr, utils.GrafanaIngress(cr))
 	}
 
-	err = r.client.Create(r.ctx, route)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err := r.applier.Apply(r.ctx, cr, utils.GrafanaIngress(cr), func(obj client.Object) error {
+		current := obj.(*ingressv1.Ingress)
+		*current = *utils.ReconciledGrafanaIngress(cr, current)
+		return nil
+	})
+	return err
 }
 
-func handleError(r *ReconcileGrafana, cr *v1alpha1.Grafana, issue error) (reconcile.Result, error) {
-	cr.Status.Phase = "failed"
+func handleError(r *ReconcileGrafana, cr *v1alpha1.Grafana, original *v1alpha1.Grafana, issue error) (reconcile.Result, error) {
+	cr.Status.Phase = v1alpha1.PhaseFailed
 	cr.Status.Message = issue.Error()
+	cr.Status.ObservedGeneration = cr.Generation
+	setCondition(cr, v1alpha1.ConditionDegraded, metav1.ConditionTrue, "ReconcileFailed", issue.Error())
 
-	err := r.client.Status().Update(r.ctx, cr)
+	err := updateStatus(r, cr, original)
 	if err != nil {
 		// Ignore conflicts, resource might just be outdated.
 		if errors.IsConflict(err) {
@@ -365,65 +495,107 @@ func handleError(r *ReconcileGrafana, cr *v1alpha1.Grafana, issue error) (reconc
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{Requeue: true, RequeueAfter: utils.RequeueDelay}, nil
+	// An invalid spec is only reconciled again when it changes
+	if _, ok := issue.(*specError); ok {
+		return reconcile.Result{}, nil
+	}
+	// A conflict only means the cache is behind, retry right away
+	if errors.IsConflict(issue) {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	delay := r.backoff.next(client.ObjectKeyFromObject(cr), issue)
+	log.Info(fmt.Sprintf("Retry to reconcile grafana in %s", delay))
+	return reconcile.Result{RequeueAfter: delay}, nil
 }
 
-func handleSucess(r *ReconcileGrafana, cr *v1alpha1.Grafana) (reconcile.Result, error) {
+func handleSucess(r *ReconcileGrafana, cr *v1alpha1.Grafana, original *v1alpha1.Grafana) (reconcile.Result, error) {
 
-	cr.Status.Phase = "reconciling"
+	cr.Status.Phase = v1alpha1.PhaseReconciling
+	if isAvailable(cr) {
+		cr.Status.Phase = v1alpha1.PhaseRunning
+	}
 	cr.Status.Message = "success"
+	cr.Status.ObservedGeneration = cr.Generation
+	// A failing grafana stays degraded even though its resources are reconciled
+	if !healthFailing(cr) {
+		setCondition(cr, v1alpha1.ConditionDegraded, metav1.ConditionFalse, "ReconcileSucceeded",
+			"all resources are reconciled")
+	}
 
-	err := r.client.Status().Update(r.ctx, cr)
+	err := updateStatus(r, cr, original)
 	if err != nil {
-		return handleError(r, cr, err)
+		return handleError(r, cr, original, err)
 	}
+	r.backoff.reset(client.ObjectKeyFromObject(cr))
 
 	log.Info("desired cluster state met")
 
-	return reconcile.Result{RequeueAfter: utils.RequeueDelay}, nil
+	// Changes are picked up by the watches, only resync when asked to.
+	resync := r.config.GetConfigDuration(config.ResyncPeriodName, 0)
+	return reconcile.Result{RequeueAfter: nextRun(r, cr, resync)}, nil
 }
 
-func reconcileDSProxyConfigSecret(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-	secret := &corev1.Secret{}
-	err := r.client.Get(r.ctx, client.ObjectKey{Namespace: cr.Namespace, Name: utils.DSProxyConfigSecName}, secret)
-	// create/update when datasource is not common service prometheus
-	//craeate
-	if err != nil && errors.IsNotFound(err) {
-		if secret, err = utils.DSProxyConfigSecret(cr, nil); err != nil {
-			return err
+// nextRun shortens resync to the next scheduled task, a credential rotation,
+// a user sync, a stale user cleanup or a health check. A task whose condition
+// reports its last run failed is retried after ConfigErrorRequeueDelay, not
+// as soon as it is due.
+func nextRun(r *ReconcileGrafana, cr *v1alpha1.Grafana, resync time.Duration) time.Duration {
+	for _, task := range []struct {
+		until     time.Duration
+		condition string
+		failed    string
+	}{
+		{untilRotation(r, cr), v1alpha1.ConditionAdminCredentialRotated, "RotationFailed"},
+		{untilUserSync(r, cr), v1alpha1.ConditionUsersSynced, "SyncFailed"},
+		{untilStaleUserCleanup(r, cr), v1alpha1.ConditionStaleUsersCleaned, "CleanupFailed"},
+		{untilHealthCheck(cr), "", ""},
+	} {
+		until := task.until
+		if until < 0 {
+			continue
 		}
-		if err = controllerutil.SetControllerReference(cr, secret, r.scheme); err != nil {
-			return err
+		if c := meta.FindStatusCondition(cr.Status.Conditions, task.condition); c != nil &&
+			c.Status == metav1.ConditionFalse && c.Reason == task.failed && until < utils.ConfigErrorRequeueDelay {
+			until = utils.ConfigErrorRequeueDelay
 		}
-		if err = r.client.Create(r.ctx, secret); err != nil {
-			return err
+		if until < utils.MinRequeueDelay {
+			until = utils.MinRequeueDelay
 		}
-		log.Info("data source configuration secret is created")
+		if resync == 0 || until < resync {
+			resync = until
+		}
+	}
+	return resync
+}
+
+// updateStatus writes the status only when it differs from the one read
+// at the beginning of the reconcile.
+func updateStatus(r *ReconcileGrafana, cr *v1alpha1.Grafana, original *v1alpha1.Grafana) error {
+	if equality.Semantic.DeepEqual(cr.Status, original.Status) {
 		return nil
 	}
+	return r.client.Status().Update(r.ctx, cr)
+}
+
+func reconcileDSProxyConfigSecret(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	secret, err := utils.DSProxyConfigSecret(cr, nil)
 	if err != nil {
 		return err
 	}
-	//update
-	if secret, err = utils.DSProxyConfigSecret(cr, secret); err != nil {
-		return err
-	}
-	if err = controllerutil.SetControllerReference(cr, secret, r.scheme); err != nil {
-		return err
-	}
-	if err = r.client.Update(r.ctx, secret); err != nil {
-		return err
-	}
-	log.Info("data source configuration secret is updated")
-	return nil
-
+	_, err = r.applier.Apply(r.ctx, cr, secret, func(obj client.Object) error {
+		current := obj.(*corev1.Secret)
+		updated, err := utils.DSProxyConfigSecret(cr, current)
+		if err != nil {
+			return err
+		}
+		*current = *updated
+		return nil
+	})
+	return err
 }
 
 func reconcileCert(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
-	certSecretName := "ibm-monitoring-certs"
-	if cr.Spec.TLSSecretName != "" {
-		certSecretName = cr.Spec.TLSSecretName
-	}
+	certSecretName := utils.CertSecretName(cr)
 	cert := utils.GetCertificate(certSecretName, cr)
 	if err := r.kclient.Get(r.ctx, client.ObjectKey{Name: cert.Name, Namespace: cert.Namespace}, cert); err != nil {
 		if errors.IsNotFound(err) {
@@ -433,16 +605,20 @@ func reconcileCert(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 			}
 			if err := r.client.Create(r.ctx, cert); err != nil {
 				log.Error(err, "fail to create certificate "+certSecretName)
+				setCondition(cr, v1alpha1.ConditionCertificateReady, metav1.ConditionFalse, "CreateFailed", err.Error())
 				return err
 			}
 			log.Info("certificate " + certSecretName + " is created")
+			setCertificateCondition(cr, cert)
 			return nil
 		}
 		log.Error(err, "fail to get certificate: "+certSecretName)
+		setCondition(cr, v1alpha1.ConditionCertificateReady, metav1.ConditionUnknown, "GetFailed", err.Error())
 		return err
 
 	}
 	log.Info("certificate " + certSecretName + " exists already")
+	setCertificateCondition(cr, cert)
 
 	return nil
 }
@@ -450,19 +626,24 @@ func checkApplicationMonitoring(r *ReconcileGrafana, cr *v1alpha1.Grafana) error
 	enabled, err := doCheckApplicationMonitoring(r)
 	if err != nil {
 		log.Error(err, "Failed to get application monitoring status")
+		setCondition(cr, v1alpha1.ConditionDatasourceReachable, metav1.ConditionUnknown, "CheckFailed", err.Error())
 		return err
 	}
 	if !enabled {
 		r.recorder.Eventf(cr, corev1.EventTypeWarning,
 			"OCP application monitoring is not enabled", "OCP application monitoring is not enabled. IBM application metrics can not be collected and related dashboards will not work")
-
+		setCondition(cr, v1alpha1.ConditionDatasourceReachable, metav1.ConditionFalse, "UserWorkloadMonitoringDisabled",
+			"OCP application monitoring is not enabled")
+		return nil
 	}
+	setCondition(cr, v1alpha1.ConditionDatasourceReachable, metav1.ConditionTrue, "UserWorkloadMonitoringEnabled",
+		"OCP application monitoring is enabled")
 
 	return nil
 
 }
 func doCheckApplicationMonitoring(r *ReconcileGrafana) (bool, error) {
-	key := client.ObjectKey{Name: "cluster-monitoring-config", Namespace: "openshift-monitoring"}
+	key := client.ObjectKey{Name: utils.ClusterMonitoringConfigName, Namespace: utils.ClusterMonitoringConfigNamespace}
 	cm := &corev1.ConfigMap{}
 	err := r.kclient.Get(r.ctx, key, cm)
 	if err != nil {
@@ -555,3 +736,31 @@ func cleanupCSMonitoring(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
 	}
 	return err
 }
+
+// migrateLegacyObjects deletes the objects created with the fixed names of the
+// single instance releases, once the grafana deployment with the names derived
+// from the CR is available. Only objects controlled by cr are deleted.
+func migrateLegacyObjects(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !isAvailable(cr) {
+		return nil
+	}
+	for _, obj := range utils.LegacyObjects(cr) {
+		err := r.client.Get(r.ctx, client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(obj, cr) {
+			continue
+		}
+		if err := r.client.Delete(r.ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info(fmt.Sprintf("Legacy object %s/%s is deleted", obj.GetNamespace(), obj.GetName()))
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "LegacyObjectDeleted",
+			"%s is replaced by an object named after the grafana resource", obj.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// checkSMTP reports whether the SMTP server accepts connections. Grafana
+// runs without it, so only an incomplete credentials or certificate secret
+// fails the reconcile: the pod would not start or not send emails.
+func checkSMTP(r *ReconcileGrafana, cr *v1alpha1.Grafana) error {
+	if !utils.SMTPEnabled(cr) {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionSMTPReachable)
+		return nil
+	}
+
+	if err := checkSMTPSecret(r, cr, cr.Spec.SMTP.SecretRef, utils.SMTPUserKey, utils.SMTPPasswordKey); err != nil {
+		return err
+	}
+	if err := checkSMTPSecret(r, cr, cr.Spec.SMTP.CertSecretRef, utils.SMTPCertKey, utils.SMTPKeyKey); err != nil {
+		return err
+	}
+
+	address := utils.SMTPAddress(cr)
+	conn, err := net.DialTimeout("tcp", address, utils.SMTPDialTimeout)
+	if err != nil {
+		log.Error(err, "SMTP server "+address+" is not reachable")
+		setCondition(cr, v1alpha1.ConditionSMTPReachable, metav1.ConditionFalse, "Unreachable",
+			fmt.Sprintf("SMTP server %s is not reachable: %v", address, err))
+		return nil
+	}
+	conn.Close()
+	setCondition(cr, v1alpha1.ConditionSMTPReachable, metav1.ConditionTrue, "Reachable",
+		fmt.Sprintf("SMTP server %s accepts connections", address))
+	return nil
+}
+
+// checkSMTPSecret makes sure a secret of the SMTP configuration has its keys
+func checkSMTPSecret(r *ReconcileGrafana, cr *v1alpha1.Grafana, ref *corev1.LocalObjectReference, keys ...string) error {
+	if ref == nil {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(r.ctx, client.ObjectKey{Name: ref.Name, Namespace: cr.Namespace}, secret); err != nil {
+		setCondition(cr, v1alpha1.ConditionSMTPReachable, metav1.ConditionFalse, "SecretNotFound",
+			fmt.Sprintf("fail to get SMTP secret %s: %v", ref.Name, err))
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			err := fmt.Errorf("SMTP secret %s has no %s key", ref.Name, key)
+			setCondition(cr, v1alpha1.ConditionSMTPReachable, metav1.ConditionFalse, "InvalidSecret", err.Error())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+	grafanaapi "github.com/IBM/ibm-monitoring-grafana-operator/pkg/grafana"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/iam"
+)
+
+var (
+	staleUsers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ibm_monitoring_grafana_stale_users",
+		Help: "Number of grafana users which are no longer IAM users, found by the last cleanup",
+	}, []string{"namespace", "name"})
+	staleUsersRemoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ibm_monitoring_grafana_stale_users_removed_total",
+		Help: "Number of stale grafana users deleted or disabled",
+	}, []string{"namespace", "name", "action"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(staleUsers, staleUsersRemoved)
+}
+
+// staleUserCleanup is what a finished cleanup reports
+type staleUserCleanup struct {
+	action string
+	dryRun bool
+	// stale is the number of stale users found, removed how many of them
+	// were deleted or disabled
+	stale   int
+	removed int
+}
+
+// untilStaleUserCleanup returns how long until the next cleanup of the stale
+// users, or a negative duration when they are not cleaned up or while a
+// cleanup runs. A failed cleanup is retried after ConfigErrorRequeueDelay.
+func untilStaleUserCleanup(r *ReconcileGrafana, cr *v1alpha1.Grafana) time.Duration {
+	if !utils.StaleUserCleanupEnabled(cr) {
+		return -1
+	}
+	if retry := r.staleUserCleanups.retryAfter(client.ObjectKeyFromObject(cr), utils.ConfigErrorRequeueDelay); retry != 0 {
+		return retry
+	}
+	if cr.Status.LastStaleUserCleanupTime == nil {
+		return 0
+	}
+	if until := time.Until(cr.Status.LastStaleUserCleanupTime.Add(utils.StaleUserCleanupInterval(cr))); until > 0 {
+		return until
+	}
+	return 0
+}
+
+// reconcileStaleUsers reports the last cleanup of the stale users and starts
+// the next one when it is due. A cleanup lists all the IAM and grafana users,
+// so it runs in the background like the user sync. A failed cleanup does not
+// block the reconcile, it is retried.
+func reconcileStaleUsers(r *ReconcileGrafana, cr *v1alpha1.Grafana) {
+	key := client.ObjectKeyFromObject(cr)
+	if !utils.StaleUserCleanupEnabled(cr) {
+		cr.Status.LastStaleUserCleanupTime = nil
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionStaleUsersCleaned)
+		deleteStaleUserMetrics(cr)
+		r.staleUserCleanups.forget(key)
+		return
+	}
+	if run := r.staleUserCleanups.unreported(key); run != nil {
+		reportStaleUsers(r, cr, run)
+	}
+	if !isAvailable(cr) || untilStaleUserCleanup(r, cr) != 0 {
+		return
+	}
+	r.staleUserCleanups.run(r.ctx, cr, "", utils.StaleUserCleanupTimeout, func(ctx context.Context, cr *v1alpha1.Grafana) (interface{}, error) {
+		cleanup := &staleUserCleanup{action: utils.StaleUserAction(cr), dryRun: cr.Spec.Auth.StaleUserCleanup.DryRun}
+		log.Info("Look for the stale users of grafana " + cr.Name)
+		var err error
+		cleanup.stale, cleanup.removed, err = cleanupStaleUsers(ctx, r, cr, cleanup.action, cleanup.dryRun)
+		return cleanup, err
+	})
+}
+
+// reportStaleUsers sets the status and the metrics of a finished cleanup
+func reportStaleUsers(r *ReconcileGrafana, cr *v1alpha1.Grafana, run *taskRun) {
+	cleanup := run.result.(*staleUserCleanup)
+	if cleanup.removed > 0 {
+		staleUsersRemoved.WithLabelValues(cr.Namespace, cr.Name, cleanup.action).Add(float64(cleanup.removed))
+	}
+	if run.err != nil {
+		log.Error(run.err, "Fail to clean up the stale users of grafana "+cr.Name)
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "StaleUserCleanupFailed",
+			"Fail to clean up the stale users, %d of %d removed: %v", cleanup.removed, cleanup.stale, run.err)
+		setCondition(cr, v1alpha1.ConditionStaleUsersCleaned, metav1.ConditionFalse, "CleanupFailed", run.err.Error())
+		return
+	}
+	staleUsers.WithLabelValues(cr.Namespace, cr.Name).Set(float64(cleanup.stale - cleanup.removed))
+	end := metav1.NewTime(run.end)
+	cr.Status.LastStaleUserCleanupTime = &end
+
+	message := fmt.Sprintf("%d stale grafana users are %sd", cleanup.removed, cleanup.action)
+	if cleanup.dryRun {
+		message = fmt.Sprintf("dry run, %d grafana users are no longer IAM users", cleanup.stale)
+	}
+	if cleanup.stale > 0 {
+		r.recorder.Event(cr, corev1.EventTypeNormal, "StaleUsersCleaned", message)
+	}
+	setCondition(cr, v1alpha1.ConditionStaleUsersCleaned, metav1.ConditionTrue, "Cleaned", message)
+}
+
+// cleanupStaleUsers removes the grafana users of cr which IAM does not know.
+// It returns the number of stale users and how many of them were removed.
+func cleanupStaleUsers(ctx context.Context, r *ReconcileGrafana, cr *v1alpha1.Grafana, action string, dryRun bool) (int, int, error) {
+	gc, admin, err := adminClient(r, cr)
+	if err != nil {
+		return 0, 0, err
+	}
+	ic, err := iamClient(r, cr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return removeStaleUsers(ctx, gc, ic, admin, action, dryRun)
+}
+
+// removeStaleUsers pages through all the grafana users but the admin and
+// deletes or disables the ones IAM does not know.
+func removeStaleUsers(ctx context.Context, gc *grafanaapi.Client, ic *iam.Client, admin, action string, dryRun bool) (int, int, error) {
+	if err := ic.Login(ctx); err != nil {
+		return 0, 0, err
+	}
+	iamUsers, err := ic.Users(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	// An empty answer is more likely an IAM problem than no user at all,
+	// it must not wipe out grafana.
+	if len(iamUsers) == 0 {
+		return 0, 0, fmt.Errorf("IAM returned no user, the stale users are kept")
+	}
+	known := map[string]bool{}
+	for _, user := range iamUsers {
+		known[user.UserID] = true
+	}
+
+	users, err := gc.AllUsers(ctx, utils.UserPageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	// One user failing does not stop the others
+	errs := []error{}
+	stale, removed := 0, 0
+	for _, user := range users {
+		if user.Login == admin || known[user.Login] {
+			continue
+		}
+		if action == v1alpha1.StaleUserDisable && user.IsDisabled {
+			continue
+		}
+		stale++
+		if dryRun {
+			log.Info("Grafana user " + user.Login + " is no longer an IAM user")
+			continue
+		}
+		if action == v1alpha1.StaleUserDisable {
+			err = gc.DisableUser(ctx, user.ID)
+		} else {
+			err = gc.DeleteUser(ctx, user.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %v", user.Login, err))
+			// The cleanup is bounded, the users left are removed next time
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		log.Info(fmt.Sprintf("Stale grafana user %s is %sd", user.Login, action))
+		removed++
+	}
+	return stale, removed, utilerrors.NewAggregate(errs)
+}
+
+// deleteStaleUserMetrics drops the metrics of cr once its users are no longer cleaned up
+func deleteStaleUserMetrics(cr *v1alpha1.Grafana) {
+	staleUsers.DeleteLabelValues(cr.Namespace, cr.Name)
+	for _, action := range []string{v1alpha1.StaleUserDelete, v1alpha1.StaleUserDisable} {
+		staleUsersRemoved.DeleteLabelValues(cr.Namespace, cr.Name, action)
+	}
+}
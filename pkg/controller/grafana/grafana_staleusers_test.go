@@ -0,0 +1,187 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+	grafanaapi "github.com/IBM/ibm-monitoring-grafana-operator/pkg/grafana"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/iam"
+)
+
+// fakeUsers serves the IAM users and the grafana users, and records the
+// users grafana is asked to remove
+type fakeUsers struct {
+	iamUsers     []iam.User
+	grafanaUsers []grafanaapi.User
+	// failures are the status codes of the removals failing, by path
+	failures map[string]int
+	removals []string
+}
+
+func (f *fakeUsers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var answer interface{}
+	switch {
+	case r.URL.Path == "/v1/auth/identitytoken":
+		answer = map[string]string{"access_token": "token"}
+	case r.URL.Path == "/identity/api/v1/users":
+		answer = f.iamUsers
+	case r.URL.Path == "/api/users/search":
+		answer = grafanaapi.UserPage{TotalCount: int64(len(f.grafanaUsers)), Users: f.grafanaUsers}
+	case strings.HasPrefix(r.URL.Path, "/api/admin/users/"):
+		f.removals = append(f.removals, r.Method+" "+r.URL.Path)
+		if status := f.failures[r.URL.Path]; status != 0 {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed"})
+			return
+		}
+		answer = map[string]string{"message": "done"}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(answer)
+}
+
+func TestRemoveStaleUsers(t *testing.T) {
+	grafanaUsers := []grafanaapi.User{
+		{ID: 1, Login: "admin"},
+		{ID: 2, Login: "alice"},
+		{ID: 3, Login: "bob"},
+		{ID: 4, Login: "carol", IsDisabled: true},
+	}
+	tests := []struct {
+		name     string
+		iamUsers []iam.User
+		failures map[string]int
+		action   string
+		dryRun   bool
+		stale    int
+		removed  int
+		removals []string
+		err      string
+	}{
+		{
+			name:     "empty IAM answer keeps every user",
+			iamUsers: []iam.User{},
+			action:   v1alpha1.StaleUserDelete,
+			err:      "IAM returned no user",
+		},
+		{
+			name:     "dry run only counts",
+			iamUsers: []iam.User{{UserID: "alice"}},
+			action:   v1alpha1.StaleUserDelete,
+			dryRun:   true,
+			stale:    2,
+		},
+		{
+			name:     "delete",
+			iamUsers: []iam.User{{UserID: "alice"}},
+			action:   v1alpha1.StaleUserDelete,
+			stale:    2,
+			removed:  2,
+			removals: []string{"DELETE /api/admin/users/3", "DELETE /api/admin/users/4"},
+		},
+		{
+			name:     "disable skips the users already disabled",
+			iamUsers: []iam.User{{UserID: "alice"}},
+			action:   v1alpha1.StaleUserDisable,
+			stale:    1,
+			removed:  1,
+			removals: []string{"POST /api/admin/users/3/disable"},
+		},
+		{
+			name:     "one user failing does not stop the others",
+			iamUsers: []iam.User{{UserID: "alice"}},
+			failures: map[string]int{"/api/admin/users/3": http.StatusInternalServerError},
+			action:   v1alpha1.StaleUserDelete,
+			stale:    2,
+			removed:  1,
+			removals: []string{"DELETE /api/admin/users/3", "DELETE /api/admin/users/4"},
+			err:      "user bob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeUsers{iamUsers: tt.iamUsers, grafanaUsers: grafanaUsers, failures: tt.failures}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			gc := grafanaapi.New(server.URL, "admin", "secret", nil)
+			ic := iam.New(server.URL, server.URL, "admin", "secret", nil)
+
+			stale, removed, err := removeStaleUsers(context.Background(), gc, ic, "admin", tt.action, tt.dryRun)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("got error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+			if stale != tt.stale || removed != tt.removed {
+				t.Errorf("got %d stale users and %d removed, want %d and %d", stale, removed, tt.stale, tt.removed)
+			}
+			if len(fake.removals) != 0 || len(tt.removals) != 0 {
+				if !reflect.DeepEqual(fake.removals, tt.removals) {
+					t.Errorf("got removals %v, want %v", fake.removals, tt.removals)
+				}
+			}
+		})
+	}
+}
+
+func TestUntilStaleUserCleanup(t *testing.T) {
+	r := &ReconcileGrafana{staleUserCleanups: newTaskRuns(nil)}
+	cr := &v1alpha1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec:       v1alpha1.GrafanaSpec{Auth: &v1alpha1.GrafanaAuth{StaleUserCleanup: &v1alpha1.StaleUserCleanup{}}},
+	}
+	key := client.ObjectKeyFromObject(cr)
+	if until := untilStaleUserCleanup(r, cr); until != 0 {
+		t.Errorf("got %v before the first cleanup, want it due", until)
+	}
+
+	r.staleUserCleanups.start(key, "")
+	if until := untilStaleUserCleanup(r, cr); until >= 0 {
+		t.Errorf("got %v while a cleanup runs, want none scheduled", until)
+	}
+
+	// A failed cleanup leaves the last cleanup time, it is retried later
+	r.staleUserCleanups.finish(key, &staleUserCleanup{}, fmt.Errorf("IAM is down"))
+	if until := untilStaleUserCleanup(r, cr); until <= utils.ConfigErrorRequeueDelay-time.Second || until > utils.ConfigErrorRequeueDelay {
+		t.Errorf("got %v after a failed cleanup, want about %v", until, utils.ConfigErrorRequeueDelay)
+	}
+
+	r.staleUserCleanups.start(key, "")
+	r.staleUserCleanups.finish(key, &staleUserCleanup{}, nil)
+	last := metav1.Now()
+	cr.Status.LastStaleUserCleanupTime = &last
+	if until := untilStaleUserCleanup(r, cr); until <= utils.DefaultStaleUserCleanupInterval-time.Second {
+		t.Errorf("got %v after a cleanup, want about %v", until, utils.DefaultStaleUserCleanupInterval)
+	}
+}
@@ -0,0 +1,158 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"strings"
+
+	cert "github.com/ibm/ibm-cert-manager-operator/apis/certmanager/v1alpha1"
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// setCondition records the result of one reconcile step in the CR status.
+// The LastTransitionTime is only changed when the status flips.
+func setCondition(cr *v1alpha1.Grafana, condType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: cr.Generation,
+	})
+}
+
+// setCertificateCondition maps the cert-manager Ready condition of the
+// serving certificate to CertificateReady.
+func setCertificateCondition(cr *v1alpha1.Grafana, certificate *cert.Certificate) {
+	for _, c := range certificate.Status.Conditions {
+		if c.Type != cert.CertificateConditionReady {
+			continue
+		}
+		if c.Status == cert.ConditionTrue {
+			setCondition(cr, v1alpha1.ConditionCertificateReady, metav1.ConditionTrue, "Issued",
+				"certificate "+certificate.Name+" is ready")
+			return
+		}
+		reason := c.Reason
+		if reason == "" {
+			reason = "NotReady"
+		}
+		setCondition(cr, v1alpha1.ConditionCertificateReady, metav1.ConditionFalse, reason, c.Message)
+		return
+	}
+	setCondition(cr, v1alpha1.ConditionCertificateReady, metav1.ConditionFalse, "Issuing",
+		"certificate "+certificate.Name+" is waiting to be issued")
+}
+
+// setDeploymentConditions derives Available and Progressing from the
+// current state of the grafana deployment.
+func setDeploymentConditions(cr *v1alpha1.Grafana, deployment *appv1.Deployment) {
+	available := false
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+			available = true
+		}
+	}
+	if available {
+		setCondition(cr, v1alpha1.ConditionAvailable, metav1.ConditionTrue, "MinimumReplicasAvailable",
+			"grafana deployment has minimum availability")
+	} else {
+		setCondition(cr, v1alpha1.ConditionAvailable, metav1.ConditionFalse, "MinimumReplicasUnavailable",
+			"grafana deployment does not have minimum availability")
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas < replicas ||
+		deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+		setCondition(cr, v1alpha1.ConditionProgressing, metav1.ConditionTrue, "RollingOut",
+			"grafana deployment is rolling out")
+	} else {
+		setCondition(cr, v1alpha1.ConditionProgressing, metav1.ConditionFalse, "RolloutComplete",
+			"grafana deployment is up to date")
+	}
+}
+
+// setStorageCondition reports whether the claim of the grafana storage is
+// bound with the requested size.
+func setStorageCondition(cr *v1alpha1.Grafana, pvc *corev1.PersistentVolumeClaim, size resource.Quantity, shrink bool) {
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	switch {
+	case pvc.Status.Phase != corev1.ClaimBound:
+		setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionFalse, "Pending",
+			"persistent volume claim "+pvc.Name+" is not bound")
+	case shrink:
+		setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionFalse, "ShrinkNotSupported",
+			fmt.Sprintf("persistent volume claim %s can not shrink to %s, it keeps %s", pvc.Name, size.String(), capacity.String()))
+	case capacity.Cmp(size) < 0:
+		setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionFalse, "Resizing",
+			fmt.Sprintf("persistent volume claim %s is expanding from %s to %s", pvc.Name, capacity.String(), size.String()))
+	default:
+		setCondition(cr, v1alpha1.ConditionStorageReady, metav1.ConditionTrue, "Bound",
+			fmt.Sprintf("persistent volume claim %s is bound with %s", pvc.Name, capacity.String()))
+	}
+}
+
+// setSecurityProfileCondition warns when spec.securityContext makes the
+// pods weaker than the restricted profile. The pods still roll out.
+func setSecurityProfileCondition(cr *v1alpha1.Grafana) {
+	violations := utils.SecurityProfileViolations(cr)
+	if len(violations) == 0 {
+		setCondition(cr, v1alpha1.ConditionSecurityProfileRestricted, metav1.ConditionTrue, "Restricted",
+			"the grafana pods meet the restricted security profile")
+		return
+	}
+	setCondition(cr, v1alpha1.ConditionSecurityProfileRestricted, metav1.ConditionFalse, "ProfileWeakened",
+		"spec.securityContext weakens the restricted security profile: "+strings.Join(violations, ", "))
+}
+
+// setDeprecatedResourcesCondition reports the deprecated resource fields
+// still set in the spec, with an event when they are first seen. They set
+// the resources while spec.size is not set and are ignored once it is.
+func setDeprecatedResourcesCondition(r *ReconcileGrafana, cr *v1alpha1.Grafana) {
+	fields := utils.DeprecatedResourceFields(cr)
+	if len(fields) == 0 {
+		setCondition(cr, v1alpha1.ConditionDeprecatedResources, metav1.ConditionFalse, "NotSet",
+			"no deprecated resource field is set")
+		return
+	}
+	reason := "Mapped"
+	message := fmt.Sprintf("%s are deprecated, set spec.size instead", strings.Join(fields, ", "))
+	if cr.Spec.Size != "" {
+		reason = "Ignored"
+		message = fmt.Sprintf("%s are deprecated and ignored, spec.size sets the resources", strings.Join(fields, ", "))
+	}
+	current := meta.FindStatusCondition(cr.Status.Conditions, v1alpha1.ConditionDeprecatedResources)
+	if current == nil || current.Status != metav1.ConditionTrue || current.Reason != reason {
+		r.recorder.Event(cr, corev1.EventTypeWarning, "DeprecatedResources", message)
+	}
+	setCondition(cr, v1alpha1.ConditionDeprecatedResources, metav1.ConditionTrue, reason, message)
+}
+
+func isAvailable(cr *v1alpha1.Grafana) bool {
+	return meta.IsStatusConditionTrue(cr.Status.Conditions, v1alpha1.ConditionAvailable)
+}
@@ -0,0 +1,151 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// taskRuns tracks a task of each CR which calls grafana or IAM for a while,
+// such as the user sync, so it runs in the background instead of holding the
+// reconcile worker. At most one run of a CR runs at a time, its end
+// reconciles the CR through done, which reports it in status.
+type taskRuns struct {
+	*sync.Mutex
+	runs map[types.NamespacedName]*taskRun
+	done chan<- event.GenericEvent
+}
+
+// taskRun is the running or the last run of the task of a CR
+type taskRun struct {
+	running  bool
+	reported bool
+	// trigger is the value of the annotation requesting the run when it started
+	trigger string
+	end     time.Time
+	result  interface{}
+	err     error
+}
+
+func newTaskRuns(done chan<- event.GenericEvent) *taskRuns {
+	return &taskRuns{
+		Mutex: &sync.Mutex{},
+		runs:  map[types.NamespacedName]*taskRun{},
+		done:  done,
+	}
+}
+
+// get returns a copy of the running or last run of a CR, nil when none ran
+func (t *taskRuns) get(key types.NamespacedName) *taskRun {
+	t.Lock()
+	defer t.Unlock()
+	run, ok := t.runs[key]
+	if !ok {
+		return nil
+	}
+	copied := *run
+	return &copied
+}
+
+// start records a new run of a CR, unless one is already running
+func (t *taskRuns) start(key types.NamespacedName, trigger string) bool {
+	t.Lock()
+	defer t.Unlock()
+	if run, ok := t.runs[key]; ok && run.running {
+		return false
+	}
+	t.runs[key] = &taskRun{running: true, trigger: trigger}
+	return true
+}
+
+func (t *taskRuns) finish(key types.NamespacedName, result interface{}, err error) {
+	t.Lock()
+	defer t.Unlock()
+	run, ok := t.runs[key]
+	if !ok {
+		return
+	}
+	run.running = false
+	run.end = time.Now()
+	run.result = result
+	run.err = err
+}
+
+// unreported returns the last run of a CR once, when it finished
+func (t *taskRuns) unreported(key types.NamespacedName) *taskRun {
+	t.Lock()
+	defer t.Unlock()
+	run, ok := t.runs[key]
+	if !ok || run.running || run.reported {
+		return nil
+	}
+	run.reported = true
+	copied := *run
+	return &copied
+}
+
+func (t *taskRuns) forget(key types.NamespacedName) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.runs, key)
+}
+
+// retryAfter returns how long until the task of a CR may run again after a
+// failed run, and a negative duration while it runs.
+func (t *taskRuns) retryAfter(key types.NamespacedName, delay time.Duration) time.Duration {
+	run := t.get(key)
+	switch {
+	case run == nil:
+		return 0
+	case run.running:
+		return -1
+	case run.err != nil:
+		if until := time.Until(run.end.Add(delay)); until > 0 {
+			return until
+		}
+	}
+	return 0
+}
+
+// run starts task on a copy of cr in the background, bounded by timeout,
+// unless it already runs. ctx stops the task with the manager.
+func (t *taskRuns) run(ctx context.Context, cr *v1alpha1.Grafana, trigger string, timeout time.Duration,
+	task func(context.Context, *v1alpha1.Grafana) (interface{}, error)) bool {
+	key := client.ObjectKeyFromObject(cr)
+	if !t.start(key, trigger) {
+		return false
+	}
+	copied := cr.DeepCopy()
+	go func() {
+		taskCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		result, err := task(taskCtx, copied)
+		t.finish(key, result, err)
+		select {
+		case t.done <- event.GenericEvent{Object: copied}:
+		case <-ctx.Done():
+		}
+	}()
+	return true
+}
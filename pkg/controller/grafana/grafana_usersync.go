@@ -0,0 +1,317 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+	grafanaapi "github.com/IBM/ibm-monitoring-grafana-operator/pkg/grafana"
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/iam"
+)
+
+// userSync keeps one grafana org per namespace an IAM team gives access to,
+// and the memberships of the IAM users in those orgs. The namespace of the
+// CR is the main org.
+type userSync struct {
+	grafana *grafanaapi.Client
+	iam     *iam.Client
+	mapping []v1alpha1.RoleMapping
+	// admin is the grafana admin, it is not an IAM user
+	admin string
+	// orgs caches the org ids by namespace
+	orgs map[string]int64
+}
+
+// userSyncResult is what a finished sync reports
+type userSyncResult struct {
+	users int
+	orgs  map[string]int64
+}
+
+// userSyncRequested is true when the sync annotation has a value which was not handled yet
+func userSyncRequested(cr *v1alpha1.Grafana) bool {
+	trigger := cr.Annotations[v1alpha1.SyncUsersAnnotation]
+	return trigger != "" && cr.Status.LastUserSyncTrigger != trigger
+}
+
+// untilUserSync returns how long until the next sync of the IAM users, or a
+// negative duration when the users are not synced or while a sync runs. A
+// failed sync is retried after ConfigErrorRequeueDelay.
+func untilUserSync(r *ReconcileGrafana, cr *v1alpha1.Grafana) time.Duration {
+	if !utils.RouterEnabled(cr) {
+		return -1
+	}
+	if retry := r.userSyncs.retryAfter(client.ObjectKeyFromObject(cr), utils.ConfigErrorRequeueDelay); retry != 0 {
+		return retry
+	}
+	if cr.Status.LastUserSyncTime == nil || userSyncRequested(cr) {
+		return 0
+	}
+	if until := time.Until(cr.Status.LastUserSyncTime.Add(utils.UserSyncInterval(cr))); until > 0 {
+		return until
+	}
+	return 0
+}
+
+// reconcileUserSync reports the last sync of the IAM users into grafana and
+// starts the next one when it is due. The router neither creates the users
+// nor their orgs in the request path: an IAM user can log in once a sync
+// added it, which the sync annotation requests right away. A failed sync
+// does not block the reconcile, it is retried.
+func reconcileUserSync(r *ReconcileGrafana, cr *v1alpha1.Grafana) {
+	key := client.ObjectKeyFromObject(cr)
+	if !utils.RouterEnabled(cr) {
+		cr.Status.LastUserSyncTime = nil
+		cr.Status.LastUserSyncTrigger = ""
+		meta.RemoveStatusCondition(&cr.Status.Conditions, v1alpha1.ConditionUsersSynced)
+		r.userSyncs.forget(key)
+		return
+	}
+	if run := r.userSyncs.unreported(key); run != nil {
+		reportUserSync(r, cr, run)
+	}
+	if !isAvailable(cr) || untilUserSync(r, cr) != 0 {
+		return
+	}
+	trigger := cr.Annotations[v1alpha1.SyncUsersAnnotation]
+	r.userSyncs.run(r.ctx, cr, trigger, utils.UserSyncTimeout, func(ctx context.Context, cr *v1alpha1.Grafana) (interface{}, error) {
+		log.Info("Sync the IAM users into grafana " + cr.Name)
+		users, orgs, err := syncUsers(ctx, r, cr)
+		return &userSyncResult{users: users, orgs: orgs}, err
+	})
+}
+
+// reportUserSync sets the status of a finished sync and hands the org ids of
+// the namespaces to the router.
+func reportUserSync(r *ReconcileGrafana, cr *v1alpha1.Grafana, run *taskRun) {
+	result := run.result.(*userSyncResult)
+	err := run.err
+	if err == nil {
+		err = reconcileGrafanaOrgs(r, cr, result.orgs)
+	}
+	if err != nil {
+		log.Error(err, "Fail to sync the IAM users into grafana "+cr.Name)
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "UserSyncFailed", "Fail to sync the IAM users: %v", err)
+		setCondition(cr, v1alpha1.ConditionUsersSynced, metav1.ConditionFalse, "SyncFailed", err.Error())
+		return
+	}
+	end := metav1.NewTime(run.end)
+	cr.Status.LastUserSyncTime = &end
+	cr.Status.LastUserSyncTrigger = run.trigger
+	setCondition(cr, v1alpha1.ConditionUsersSynced, metav1.ConditionTrue, "Synced",
+		fmt.Sprintf("%d IAM users are synced into %d organizations", result.users, len(result.orgs)))
+}
+
+// reconcileGrafanaOrgs writes the org id of each namespace for the router
+func reconcileGrafanaOrgs(r *ReconcileGrafana, cr *v1alpha1.Grafana, orgs map[string]int64) error {
+	desired, err := utils.GrafanaOrgsConfigMap(cr, orgs)
+	if err != nil {
+		return err
+	}
+	_, err = r.applier.Apply(r.ctx, cr, desired, func(obj client.Object) error {
+		current := obj.(*corev1.ConfigMap)
+		current.Labels = desired.Labels
+		current.Data = desired.Data
+		return nil
+	})
+	return err
+}
+
+// syncUsers syncs every IAM user and returns how many were synced and the
+// org id of each namespace.
+func syncUsers(ctx context.Context, r *ReconcileGrafana, cr *v1alpha1.Grafana) (int, map[string]int64, error) {
+	gc, admin, err := adminClient(r, cr)
+	if err != nil {
+		return 0, nil, err
+	}
+	ic, err := iamClient(r, cr)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := ic.Login(ctx); err != nil {
+		return 0, nil, err
+	}
+	users, err := ic.Users(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s := &userSync{
+		grafana: gc,
+		iam:     ic,
+		mapping: utils.RoleMapping(cr),
+		admin:   admin,
+		orgs:    map[string]int64{cr.Namespace: grafanaapi.MainOrgID},
+	}
+	// One user failing does not stop the others
+	errs := []error{}
+	synced := 0
+	for _, user := range users {
+		if user.UserID == "" || user.UserID == s.admin {
+			continue
+		}
+		if err := s.syncUser(ctx, user.UserID); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %v", user.UserID, err))
+			// The sync is bounded, the users left are synced next time
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		synced++
+	}
+	return synced, s.orgs, utilerrors.NewAggregate(errs)
+}
+
+// syncUser creates the grafana user of an IAM user with access to some
+// namespaces and makes its org memberships match its namespaces.
+func (s *userSync) syncUser(ctx context.Context, login string) error {
+	namespaces, err := s.iam.UserNamespaces(ctx, login)
+	if err != nil {
+		return err
+	}
+	desired := map[int64]string{}
+	for _, ns := range namespaces {
+		orgID, err := s.orgID(ctx, ns.NamespaceID)
+		if err != nil {
+			return err
+		}
+		desired[orgID] = grafanaRole(s.mapping, ns)
+	}
+
+	user, err := s.grafana.LookupUser(ctx, login)
+	if grafanaapi.IsStatus(err, http.StatusNotFound) {
+		if len(desired) == 0 {
+			return nil
+		}
+		user, err = s.createUser(ctx, login)
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := s.grafana.UserOrgs(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	for _, org := range current {
+		role, ok := desired[org.OrgID]
+		switch {
+		case !ok:
+			err = s.grafana.RemoveOrgUser(ctx, org.OrgID, user.ID)
+		case role != org.Role:
+			err = s.grafana.UpdateOrgUser(ctx, org.OrgID, user.ID, role)
+		}
+		if err != nil {
+			return err
+		}
+		delete(desired, org.OrgID)
+	}
+	for orgID, role := range desired {
+		if err := s.grafana.AddOrgUser(ctx, orgID, login, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createUser creates a grafana user, it only logs in through the router
+// so its password is random and never used.
+func (s *userSync) createUser(ctx context.Context, login string) (*grafanaapi.User, error) {
+	password, err := utils.GeneratePassword()
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.grafana.CreateUser(ctx, grafanaapi.NewUser{Login: login, Name: login, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created grafana user " + login)
+	return &grafanaapi.User{ID: id, Login: login}, nil
+}
+
+// orgID returns the id of the org of a namespace, created when missing
+func (s *userSync) orgID(ctx context.Context, namespace string) (int64, error) {
+	if id, ok := s.orgs[namespace]; ok {
+		return id, nil
+	}
+	org, err := s.grafana.LookupOrg(ctx, namespace)
+	var id int64
+	switch {
+	case err == nil:
+		id = org.ID
+	case grafanaapi.IsStatus(err, http.StatusNotFound):
+		if id, err = s.grafana.CreateOrg(ctx, namespace); err != nil {
+			return 0, err
+		}
+		log.Info("Created grafana organization " + namespace)
+	default:
+		return 0, err
+	}
+	s.orgs[namespace] = id
+	return id, nil
+}
+
+// grafanaRole returns the role of the first mapping matching the IAM role,
+// or the IAM actions when IAM reports no role. Unmatched users are viewers.
+func grafanaRole(mapping []v1alpha1.RoleMapping, ns iam.Namespace) string {
+	for _, m := range mapping {
+		if ns.HighestRole != "" && m.IAMRole == ns.HighestRole {
+			return m.GrafanaRole
+		}
+		if ns.HighestRole == "" && m.Actions != "" && m.Actions == ns.Actions {
+			return m.GrafanaRole
+		}
+	}
+	return v1alpha1.GrafanaRoleViewer
+}
+
+// iamClient returns a client of the IAM services in the namespace of cr.
+// They are served with certificates of the same CA as grafana.
+func iamClient(r *ReconcileGrafana, cr *v1alpha1.Grafana) (*iam.Client, error) {
+	credentials := &corev1.Secret{}
+	key := client.ObjectKey{Name: utils.IAMCredentialsSecretName(cr), Namespace: cr.Namespace}
+	if err := r.client.Get(r.ctx, key, credentials); err != nil {
+		return nil, err
+	}
+	user := string(credentials.Data[utils.IAMAdminUserKey])
+	password := string(credentials.Data[utils.IAMAdminPasswordKey])
+	if user == "" || password == "" {
+		return nil, fmt.Errorf("IAM credentials secret %s has no %s or %s key", key.Name,
+			utils.IAMAdminUserKey, utils.IAMAdminPasswordKey)
+	}
+
+	certs := &corev1.Secret{}
+	if err := r.client.Get(r.ctx, client.ObjectKey{Name: utils.CertSecretName(cr), Namespace: cr.Namespace}, certs); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := grafanaapi.TLSConfig(certs.Data["ca.crt"], nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return iam.New(utils.IAMProviderURL(cr), utils.IAMManagementURL(cr), user, password, tlsConfig), nil
+}
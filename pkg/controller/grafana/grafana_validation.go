@@ -0,0 +1,615 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	utils "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/model"
+)
+
+// specError is a spec the operator refuses to roll out. Retrying does not
+// help, the CR is reconciled again once its spec changes.
+type specError struct {
+	reason  string
+	message string
+}
+
+func (e *specError) Error() string {
+	return e.message
+}
+
+// checkSpec validates the spec before anything is created or updated and
+// reports the result in the SpecValid condition.
+func checkSpec(cr *v1alpha1.Grafana) error {
+	validations := []func(*v1alpha1.Grafana) *specError{
+		validateDatabase,
+		validateReplicas,
+		validateAuth,
+		validateRoleMapping,
+		validateStaleUserCleanup,
+		validateGrafanaINI,
+		validateSMTP,
+		validateINIValues,
+		validatePlugins,
+		validateScheduling,
+		validateSecurityContext,
+		validateSize,
+		validateAutoscaling,
+		validatePodDisruptionBudget,
+	}
+	for _, validate := range validations {
+		if err := validate(cr); err != nil {
+			setCondition(cr, v1alpha1.ConditionSpecValid, metav1.ConditionFalse, err.reason, err.message)
+			return err
+		}
+	}
+	setCondition(cr, v1alpha1.ConditionSpecValid, metav1.ConditionTrue, "Valid", "the spec is valid")
+	return nil
+}
+
+func validateDatabase(cr *v1alpha1.Grafana) *specError {
+	switch utils.DatabaseType(cr) {
+	case v1alpha1.DatabaseSQLite:
+		return nil
+	case v1alpha1.DatabaseMySQL, v1alpha1.DatabasePostgres:
+		if cr.Spec.Database.Host == "" {
+			return &specError{"InvalidDatabase",
+				fmt.Sprintf("spec.database.host is required for a %s database", cr.Spec.Database.Type)}
+		}
+		return nil
+	default:
+		return &specError{"InvalidDatabase",
+			fmt.Sprintf("spec.database.type %s is not one of sqlite3, mysql or postgres", cr.Spec.Database.Type)}
+	}
+}
+
+// validateReplicas refuses several replicas on top of sqlite: every pod would
+// have its own users, dashboards and login sessions, or corrupt a shared file.
+func validateReplicas(cr *v1alpha1.Grafana) *specError {
+	if utils.Replicas(cr) < 0 {
+		return &specError{"InvalidReplicas", "spec.replicas can not be negative"}
+	}
+	replicas := utils.MaxReplicas(cr)
+	if replicas <= 1 || utils.ExternalDatabase(cr) {
+		return nil
+	}
+	field, fix := "spec.replicas", "set spec.replicas to 1"
+	if utils.HorizontalAutoscalingEnabled(cr) {
+		field, fix = "spec.autoscaling.hpa.maxReplicas", "set spec.autoscaling.hpa.maxReplicas to 1"
+	}
+	storage := "an emptyDir volume private to each pod"
+	if utils.PersistentVolumeEnabled(cr) {
+		storage = "a persistent volume, which sqlite can not safely share between pods"
+	}
+	return &specError{"UnsafeReplicas",
+		fmt.Sprintf("%s is %d but grafana keeps its users, dashboards and login sessions in sqlite on %s. "+
+			"Set spec.database to a mysql or postgres database shared by all the replicas, or %s",
+			field, replicas, storage, fix)}
+}
+
+func validateAuth(cr *v1alpha1.Grafana) *specError {
+	switch utils.AuthMode(cr) {
+	case v1alpha1.AuthModeIAMRouter:
+		return nil
+	case v1alpha1.AuthModeProxyHeader:
+		// Grafana logs in whoever the header names, only the proxy may send it
+		proxy := cr.Spec.Auth.ProxyHeader
+		if proxy == nil || len(proxy.Whitelist) == 0 {
+			return &specError{"InvalidAuth",
+				"spec.auth.proxyHeader.whitelist is required when spec.auth.mode is proxy-header, " +
+					"without it anybody reaching grafana can log in as any user"}
+		}
+		for _, address := range proxy.Whitelist {
+			if net.ParseIP(address) == nil {
+				if _, _, err := net.ParseCIDR(address); err != nil {
+					return &specError{"InvalidAuth",
+						fmt.Sprintf("spec.auth.proxyHeader.whitelist entry %q is not an IP address or a CIDR", address)}
+				}
+			}
+		}
+		return nil
+	case v1alpha1.AuthModeOIDC:
+		oidc := cr.Spec.Auth.OIDC
+		if oidc == nil {
+			return &specError{"InvalidAuth", "spec.auth.oidc is required when spec.auth.mode is oidc"}
+		}
+		missing := []string{}
+		for field, value := range map[string]string{
+			"clientID": oidc.ClientID,
+			"authURL":  oidc.AuthURL,
+			"tokenURL": oidc.TokenURL,
+			"apiURL":   oidc.APIURL,
+		} {
+			if value == "" {
+				missing = append(missing, "spec.auth.oidc."+field)
+			}
+		}
+		if oidc.ClientSecretRef == nil || oidc.ClientSecretRef.Name == "" || oidc.ClientSecretRef.Key == "" {
+			missing = append(missing, "spec.auth.oidc.clientSecretRef")
+		}
+		if cr.Spec.Auth.RootURL == "" {
+			missing = append(missing, "spec.auth.rootURL")
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return &specError{"InvalidAuth",
+				fmt.Sprintf("spec.auth.mode oidc requires %s", strings.Join(missing, ", "))}
+		}
+		return nil
+	default:
+		return &specError{"InvalidAuth",
+			fmt.Sprintf("spec.auth.mode %s is not one of iam-router, oidc or proxy-header", cr.Spec.Auth.Mode)}
+	}
+}
+
+// roleNamePattern matches the IAM role and action names
+var roleNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func validateRoleMapping(cr *v1alpha1.Grafana) *specError {
+	if cr.Spec.Auth == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	for i, m := range cr.Spec.Auth.RoleMapping {
+		field := fmt.Sprintf("spec.auth.roleMapping[%d]", i)
+		if (m.IAMRole == "") == (m.Actions == "") {
+			return &specError{"InvalidRoleMapping", field + " must set one of iamRole or actions"}
+		}
+		name := m.IAMRole
+		key := "iamRole " + m.IAMRole
+		if m.Actions != "" {
+			name = m.Actions
+			key = "actions " + m.Actions
+		}
+		if !roleNamePattern.MatchString(name) {
+			return &specError{"InvalidRoleMapping",
+				fmt.Sprintf("%s %s can only contain letters, digits, '_', '.' and '-'", field, key)}
+		}
+		if seen[key] {
+			return &specError{"InvalidRoleMapping", fmt.Sprintf("%s maps %s twice", field, key)}
+		}
+		seen[key] = true
+		switch m.GrafanaRole {
+		case v1alpha1.GrafanaRoleAdmin, v1alpha1.GrafanaRoleEditor, v1alpha1.GrafanaRoleViewer:
+		default:
+			return &specError{"InvalidRoleMapping",
+				fmt.Sprintf("%s grafanaRole %s is not one of Admin, Editor or Viewer", field, m.GrafanaRole)}
+		}
+	}
+	return nil
+}
+
+func validateStaleUserCleanup(cr *v1alpha1.Grafana) *specError {
+	if cr.Spec.Auth == nil || cr.Spec.Auth.StaleUserCleanup == nil {
+		return nil
+	}
+	if !utils.RouterEnabled(cr) {
+		return &specError{"InvalidStaleUserCleanup",
+			fmt.Sprintf("spec.auth.staleUserCleanup requires spec.auth.mode iam-router, not %s", utils.AuthMode(cr))}
+	}
+	switch cr.Spec.Auth.StaleUserCleanup.Action {
+	case "", v1alpha1.StaleUserDelete, v1alpha1.StaleUserDisable:
+		return nil
+	default:
+		return &specError{"InvalidStaleUserCleanup",
+			fmt.Sprintf("spec.auth.staleUserCleanup.action %s is not one of delete or disable",
+				cr.Spec.Auth.StaleUserCleanup.Action)}
+	}
+}
+
+var (
+	// iniSectionPattern matches the grafana.ini sections, e.g. auth.generic_oauth
+	iniSectionPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	iniKeyPattern     = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// validateGrafanaINI refuses the overrides which would not render a valid
+// grafana.ini or which change a key the operator relies on.
+func validateGrafanaINI(cr *v1alpha1.Grafana) *specError {
+	overrides := utils.INIOverrides(cr)
+	sections := make([]string, 0, len(overrides))
+	for section := range overrides {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	protected := []string{}
+	for _, section := range sections {
+		if !iniSectionPattern.MatchString(section) {
+			return &specError{"InvalidGrafanaConfig",
+				fmt.Sprintf("spec.grafanaConfig.ini section %q can only contain letters, digits, '_', '.' and '-'", section)}
+		}
+		keys := make([]string, 0, len(overrides[section]))
+		for key := range overrides[section] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !iniKeyPattern.MatchString(key) {
+				return &specError{"InvalidGrafanaConfig",
+					fmt.Sprintf("spec.grafanaConfig.ini key %q of section %s can only contain letters, digits and '_'", key, section)}
+			}
+			if utils.ProtectedINIKey(section, key) {
+				protected = append(protected, section+"."+key)
+			}
+		}
+	}
+	if len(protected) > 0 {
+		return &specError{"InvalidGrafanaConfig",
+			fmt.Sprintf("spec.grafanaConfig.ini can not set %s, the operator manages them", strings.Join(protected, ", "))}
+	}
+	return nil
+}
+
+func validateSMTP(cr *v1alpha1.Grafana) *specError {
+	smtp := cr.Spec.SMTP
+	if smtp == nil {
+		return nil
+	}
+	if smtp.Host == "" {
+		return &specError{"InvalidSMTP", "spec.smtp.host is required"}
+	}
+	if _, err := mail.ParseAddress(smtp.FromAddress); err != nil {
+		return &specError{"InvalidSMTP",
+			fmt.Sprintf("spec.smtp.fromAddress %q is not an email address: %v", smtp.FromAddress, err)}
+	}
+	if smtp.SecretRef != nil && smtp.SecretRef.Name == "" {
+		return &specError{"InvalidSMTP", "spec.smtp.secretRef.name is required"}
+	}
+	if smtp.CertSecretRef != nil && smtp.CertSecretRef.Name == "" {
+		return &specError{"InvalidSMTP", "spec.smtp.certSecretRef.name is required"}
+	}
+	switch smtp.StartTLSPolicy {
+	case "", v1alpha1.SMTPOpportunisticStartTLS, v1alpha1.SMTPMandatoryStartTLS, v1alpha1.SMTPNoStartTLS:
+		return nil
+	default:
+		return &specError{"InvalidSMTP",
+			fmt.Sprintf("spec.smtp.startTLSPolicy %s is not one of OpportunisticStartTLS, MandatoryStartTLS or NoStartTLS",
+				smtp.StartTLSPolicy)}
+	}
+}
+
+// iniValues returns the spec fields rendered into grafana.ini by their path
+func iniValues(cr *v1alpha1.Grafana) map[string]string {
+	values := map[string]string{}
+	for section, keys := range utils.INIOverrides(cr) {
+		for key, value := range keys {
+			values[fmt.Sprintf("spec.grafanaConfig.ini %s.%s", section, key)] = value
+		}
+	}
+	if auth := cr.Spec.Auth; auth != nil {
+		values["spec.auth.rootURL"] = auth.RootURL
+		if oidc := auth.OIDC; oidc != nil {
+			values["spec.auth.oidc.name"] = oidc.Name
+			values["spec.auth.oidc.clientID"] = oidc.ClientID
+			values["spec.auth.oidc.authURL"] = oidc.AuthURL
+			values["spec.auth.oidc.tokenURL"] = oidc.TokenURL
+			values["spec.auth.oidc.apiURL"] = oidc.APIURL
+			values["spec.auth.oidc.roleAttributePath"] = oidc.RoleAttributePath
+			for i, scope := range oidc.Scopes {
+				values[fmt.Sprintf("spec.auth.oidc.scopes[%d]", i)] = scope
+			}
+		}
+		if proxy := auth.ProxyHeader; proxy != nil {
+			values["spec.auth.proxyHeader.headerName"] = proxy.HeaderName
+			values["spec.auth.proxyHeader.headerProperty"] = proxy.HeaderProperty
+			for i, address := range proxy.Whitelist {
+				values[fmt.Sprintf("spec.auth.proxyHeader.whitelist[%d]", i)] = address
+			}
+		}
+	}
+	if smtp := cr.Spec.SMTP; smtp != nil {
+		values["spec.smtp.host"] = smtp.Host
+		values["spec.smtp.fromAddress"] = smtp.FromAddress
+		values["spec.smtp.fromName"] = smtp.FromName
+		values["spec.smtp.ehloIdentity"] = smtp.EHLOIdentity
+	}
+	if db := cr.Spec.Database; db != nil {
+		values["spec.database.type"] = db.Type
+		values["spec.database.host"] = db.Host
+		values["spec.database.name"] = db.Name
+	}
+	return values
+}
+
+// validateINIValues refuses the line breaks in the fields rendered into
+// grafana.ini, they would add keys or sections of their own.
+func validateINIValues(cr *v1alpha1.Grafana) *specError {
+	values := iniValues(cr)
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if strings.ContainsAny(values[field], "\r\n") {
+			return &specError{"InvalidGrafanaConfig", field + " can not span several lines"}
+		}
+	}
+	return nil
+}
+
+var (
+	// pluginNamePattern matches the grafana plugin ids, e.g. grafana-piechart-panel
+	pluginNamePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	pluginVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+)
+
+// validatePlugins refuses the plugins which can not be passed safely to
+// grafana-cli in the install script.
+func validatePlugins(cr *v1alpha1.Grafana) *specError {
+	seen := map[string]bool{}
+	for i, plugin := range cr.Spec.Plugins {
+		field := fmt.Sprintf("spec.plugins[%d]", i)
+		if !pluginNamePattern.MatchString(plugin.Name) {
+			return &specError{"InvalidPlugin",
+				fmt.Sprintf("%s name %q can only contain lowercase letters, digits, '.', '_' and '-'", field, plugin.Name)}
+		}
+		if seen[plugin.Name] {
+			return &specError{"InvalidPlugin", fmt.Sprintf("%s installs %s twice", field, plugin.Name)}
+		}
+		seen[plugin.Name] = true
+		if plugin.Version != "" && !pluginVersionPattern.MatchString(plugin.Version) {
+			return &specError{"InvalidPlugin", fmt.Sprintf("%s version %q is not a version", field, plugin.Version)}
+		}
+
+		sources := 0
+		if plugin.URL != "" {
+			sources++
+			u, err := url.Parse(plugin.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+				strings.ContainsAny(plugin.URL, "'") {
+				return &specError{"InvalidPlugin", fmt.Sprintf("%s url %q is not an http or https URL", field, plugin.URL)}
+			}
+		}
+		if cm := plugin.ConfigMap; cm != nil {
+			sources++
+			if cm.Name == "" || cm.Key == "" || strings.ContainsAny(cm.Key, "'/") {
+				return &specError{"InvalidPlugin", field + " configMap requires a name and a key"}
+			}
+		}
+		if claim := plugin.PersistentVolumeClaim; claim != nil {
+			sources++
+			clean := path.Clean("/" + claim.Path)
+			if claim.ClaimName == "" || claim.Path == "" || clean != "/"+strings.TrimPrefix(claim.Path, "/") ||
+				strings.ContainsAny(claim.Path, "'") {
+				return &specError{"InvalidPlugin",
+					field + " persistentVolumeClaim requires a claimName and a path inside the volume"}
+			}
+		}
+		if sources > 1 {
+			return &specError{"InvalidPlugin", field + " can only set one of url, configMap or persistentVolumeClaim"}
+		}
+	}
+	return nil
+}
+
+// validateScheduling checks what the CRD schema can not: the values the
+// scheduler accepts for the tolerations and topology spread constraints.
+func validateScheduling(cr *v1alpha1.Grafana) *specError {
+	for i, t := range cr.Spec.Tolerations {
+		field := fmt.Sprintf("spec.tolerations[%d]", i)
+		switch t.Operator {
+		case "", corev1.TolerationOpEqual:
+		case corev1.TolerationOpExists:
+			if t.Value != "" {
+				return &specError{"InvalidScheduling", field + " value must be empty with the Exists operator"}
+			}
+		default:
+			return &specError{"InvalidScheduling",
+				fmt.Sprintf("%s operator %s is not one of Equal or Exists", field, t.Operator)}
+		}
+		if t.Key == "" && t.Operator != corev1.TolerationOpExists {
+			return &specError{"InvalidScheduling", field + " without a key requires the Exists operator"}
+		}
+		switch t.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return &specError{"InvalidScheduling",
+				fmt.Sprintf("%s effect %s is not one of NoSchedule, PreferNoSchedule or NoExecute", field, t.Effect)}
+		}
+		if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+			return &specError{"InvalidScheduling", field + " tolerationSeconds requires the NoExecute effect"}
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, c := range cr.Spec.TopologySpreadConstraints {
+		field := fmt.Sprintf("spec.topologySpreadConstraints[%d]", i)
+		if c.MaxSkew < 1 {
+			return &specError{"InvalidScheduling", field + " maxSkew must be at least 1"}
+		}
+		if c.TopologyKey == "" {
+			return &specError{"InvalidScheduling", field + " topologyKey is required"}
+		}
+		switch c.WhenUnsatisfiable {
+		case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+		default:
+			return &specError{"InvalidScheduling",
+				fmt.Sprintf("%s whenUnsatisfiable %q is not one of DoNotSchedule or ScheduleAnyway", field, c.WhenUnsatisfiable)}
+		}
+		key := c.TopologyKey + "/" + string(c.WhenUnsatisfiable)
+		if seen[key] {
+			return &specError{"InvalidScheduling",
+				fmt.Sprintf("%s repeats topologyKey %s with %s", field, c.TopologyKey, c.WhenUnsatisfiable)}
+		}
+		seen[key] = true
+	}
+
+	if name := cr.Spec.PriorityClassName; name != "" {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return &specError{"InvalidScheduling",
+				fmt.Sprintf("spec.priorityClassName %s is invalid: %s", name, strings.Join(errs, ", "))}
+		}
+	}
+	return nil
+}
+
+// validateSecurityContext refuses overrides of containers the pods do not have,
+// the containers depend on the login mode and the plugins.
+func validateSecurityContext(cr *v1alpha1.Grafana) *specError {
+	if cr.Spec.SecurityContext == nil || len(cr.Spec.SecurityContext.Containers) == 0 {
+		return nil
+	}
+	spec := utils.GrafanaDeployment(cr).Spec.Template.Spec
+	known := map[string]bool{}
+	names := []string{}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		known[c.Name] = true
+		names = append(names, c.Name)
+	}
+	unknown := []string{}
+	for name := range cr.Spec.SecurityContext.Containers {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		sort.Strings(names)
+		return &specError{"InvalidSecurityContext",
+			fmt.Sprintf("spec.securityContext.containers %s are not containers of the grafana pods, which are %s",
+				strings.Join(unknown, ", "), strings.Join(names, ", "))}
+	}
+	return nil
+}
+
+func validateSize(cr *v1alpha1.Grafana) *specError {
+	switch cr.Spec.Size {
+	case "", v1alpha1.SizeSmall, v1alpha1.SizeMedium, v1alpha1.SizeLarge:
+		if cr.Spec.CustomResources != nil {
+			return &specError{"InvalidSize", "spec.customResources is only used with spec.size custom"}
+		}
+		return nil
+	case v1alpha1.SizeCustom:
+	default:
+		return &specError{"InvalidSize",
+			fmt.Sprintf("spec.size %s is not one of small, medium, large or custom", cr.Spec.Size)}
+	}
+	custom := cr.Spec.CustomResources
+	if custom == nil {
+		return nil
+	}
+	for _, c := range []struct {
+		field     string
+		resources *corev1.ResourceRequirements
+	}{
+		{"grafana", custom.Grafana},
+		{"router", custom.Router},
+		{"dashboard", custom.Dashboard},
+		{"dsProxy", custom.DSProxy},
+		{"init", custom.Init},
+	} {
+		if c.resources == nil {
+			continue
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			request, hasRequest := c.resources.Requests[name]
+			limit, hasLimit := c.resources.Limits[name]
+			if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+				return &specError{"InvalidSize",
+					fmt.Sprintf("spec.customResources.%s requests %s %s, more than its limit %s",
+						c.field, request.String(), name, limit.String())}
+			}
+		}
+	}
+	return nil
+}
+
+// validateAutoscaling checks the bounds of the HPA and the VPA. A VPA in auto
+// mode would fight the HPA, both act on the CPU and memory of the pods.
+func validateAutoscaling(cr *v1alpha1.Grafana) *specError {
+	if utils.HorizontalAutoscalingEnabled(cr) {
+		hpa := cr.Spec.Autoscaling.HPA
+		if hpa.MaxReplicas < 1 {
+			return &specError{"InvalidAutoscaling", "spec.autoscaling.hpa.maxReplicas must be at least 1"}
+		}
+		if min := utils.MinReplicas(cr); min < 1 || min > hpa.MaxReplicas {
+			return &specError{"InvalidAutoscaling",
+				fmt.Sprintf("spec.autoscaling.hpa.minReplicas %d is not between 1 and maxReplicas %d", min, hpa.MaxReplicas)}
+		}
+		for field, target := range map[string]*int32{
+			"targetCPUUtilization":    hpa.TargetCPUUtilization,
+			"targetMemoryUtilization": hpa.TargetMemoryUtilization,
+		} {
+			if target != nil && *target < 1 {
+				return &specError{"InvalidAutoscaling", "spec.autoscaling.hpa." + field + " must be at least 1"}
+			}
+		}
+	}
+	if !utils.VerticalAutoscalingEnabled(cr) {
+		return nil
+	}
+	vpa := cr.Spec.Autoscaling.VPA
+	switch vpa.Mode {
+	case "", v1alpha1.VPAModeRecommend:
+	case v1alpha1.VPAModeAuto:
+		if utils.HorizontalAutoscalingEnabled(cr) {
+			return &specError{"ConflictingAutoscalers",
+				"spec.autoscaling.vpa in auto mode and spec.autoscaling.hpa both scale on the CPU and memory of the pods. " +
+					"Set spec.autoscaling.vpa.mode to recommend or remove one of them"}
+		}
+	default:
+		return &specError{"InvalidAutoscaling",
+			fmt.Sprintf("spec.autoscaling.vpa.mode %s is not one of recommend or auto", vpa.Mode)}
+	}
+	for name, max := range vpa.MaxAllowed {
+		if min, ok := vpa.MinAllowed[name]; ok && min.Cmp(max) > 0 {
+			return &specError{"InvalidAutoscaling",
+				fmt.Sprintf("spec.autoscaling.vpa.minAllowed %s is above maxAllowed", name)}
+		}
+	}
+	return nil
+}
+
+// validatePodDisruptionBudget checks that the budget sets one valid number or percentage
+func validatePodDisruptionBudget(cr *v1alpha1.Grafana) *specError {
+	config := cr.Spec.PodDisruptionBudget
+	if config == nil {
+		return nil
+	}
+	if config.MinAvailable != nil && config.MaxUnavailable != nil {
+		return &specError{"InvalidPodDisruptionBudget",
+			"spec.podDisruptionBudget sets both minAvailable and maxUnavailable, set only one of them"}
+	}
+	for field, value := range map[string]*intstr.IntOrString{
+		"minAvailable":   config.MinAvailable,
+		"maxUnavailable": config.MaxUnavailable,
+	} {
+		if value == nil {
+			continue
+		}
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+		if err != nil || scaled < 0 || (value.Type == intstr.String && scaled > 100) {
+			return &specError{"InvalidPodDisruptionBudget",
+				fmt.Sprintf("spec.podDisruptionBudget.%s %s is not a non negative number or a percentage", field, value.String())}
+		}
+	}
+	return nil
+}
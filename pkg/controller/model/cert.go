@@ -20,11 +20,19 @@ import (
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
 )
 
+// CertSecretName returns the name of the serving certificate and its secret
+func CertSecretName(cr *v1alpha1.Grafana) string {
+	if cr.Spec.TLSSecretName != "" {
+		return cr.Spec.TLSSecretName
+	}
+	return instanceName(cr, "grafana-certs")
+}
+
 func GetCertificate(name string, cr *v1alpha1.Grafana) *cert.Certificate {
 	dnsNames := []string{}
 	dnsNames = append(dnsNames,
-		GrafanaServiceName,
-		GrafanaServiceName+"."+cr.Namespace,
+		ServiceName(cr),
+		ServiceName(cr)+"."+cr.Namespace,
 		"*."+cr.Namespace,
 		"*."+cr.Namespace+".svc")
 	return &cert.Certificate{
@@ -27,6 +27,7 @@ const (
 	GrafanaDatasourceName                    = "datasource-config"
 	GrafanaHealthEndpoint                    = "/api/health"
 	DefaultRouterPort                        = 8080
+	DefaultRouterServicePort           int32 = 8445
 	DefaultClusterPort                 int32 = 8443
 	GrafanaAdminSecretName                   = "grafana-secret"
 	GrafanaInitMounts                        = "grafana-init-mount"
@@ -37,10 +38,15 @@ const (
 	GrafanaServiceName                       = "ibm-monitoring-grafana"
 	GrafanaHTTPPortName                      = "web"
 	RequeueDelay                             = time.Second * 10
+	MinRequeueDelay                          = time.Second * 5
+	ConfigErrorRequeueDelay                  = time.Minute
+	MaxRequeueDelay                          = time.Minute * 10
 	DefaultGrafanaPort                 int32 = 3000
 	GrafanaRouteName                         = "ibm-monitoring-grafana"
 	GrafanaAdminUserEnvVar                   = "username"
 	GrafanaAdminPasswordEnvVar               = "password"
+	DefaultAdminUser                         = "admin"
+	ConfigHashAnnotation                     = "operator.ibm.com/config-hash"
 	ClusterDomain                            = "cluster.local"
 	InitContainerName                        = "init-container"
 	DefaultInitImage                         = "quay.io/opencloudio/icp-initcontainer"
@@ -52,12 +58,28 @@ const (
 	DefaultRouterImage                       = "quay.io/opencloudio/icp-management-ingress"
 	DefaultRouterImageTag                    = "2.5.1"
 	DSProxyConfigSecName                     = "grafana-ds-proxy-config"
+	DefaultCertSecretName                    = "ibm-monitoring-certs"
+	DefaultStorageSize                       = "1Gi"
+	DatabaseDialTimeout                      = time.Second * 5
+	SMTPDialTimeout                          = time.Second * 5
+	DefaultUserSyncInterval                  = time.Minute * 5
+	UserSyncTimeout                          = time.Minute * 10
+	GrafanaOrgsKey                           = "orgs.json"
+	HealthCheckInterval                      = time.Minute * 2
+	DefaultStaleUserCleanupInterval          = time.Hour * 24
+	StaleUserCleanupTimeout                  = time.Minute * 10
+	UserPageSize                             = 100
+	DefaultIAMCredentialsSecretName          = "platform-auth-idp-credentials"
+	IAMManagementPort                        = "4500"
+	ClusterMonitoringConfigName              = "cluster-monitoring-config"
+	ClusterMonitoringConfigNamespace         = "openshift-monitoring"
 
 	grafanaImageEnv      = "GRAFANA_IMAGE"
 	routerImageEnv       = "ICP_MANAGEMENT_INGRESS_IMAGE"
 	dsProxyImageEnv      = "GRAFANA_OCPTHANOS_PROXY_IMAGE"
 	dashboardCtlImageEnv = "DASHBOARD_CONTROLLER_IMAGE"
 	imageDigestKey       = `sha256:`
+	passwordBytes        = 24
 
 	//CS Monitoring resources to be cleanedup
 	CollectdDeploymentName           = "ibm-monitoring-collectd"
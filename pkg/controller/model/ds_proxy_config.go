@@ -36,7 +36,7 @@ func DSProxyConfigSecret(cr *v1alpha1.Grafana, osecret *corev1.Secret) (*corev1.
 	if osecret == nil {
 		secret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      DSProxyConfigSecName,
+				Name:      DSProxyConfigSecretName(cr),
 				Namespace: cr.Namespace,
 				Labels:    labels,
 			},
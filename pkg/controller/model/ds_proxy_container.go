@@ -19,26 +19,12 @@ import (
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
 )
 
 func dsProxyContainer(cr *v1alpha1.Grafana) *corev1.Container {
 
-	resources := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("16Mi"),
-			corev1.ResourceCPU:    resource.MustParse("5m"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-			corev1.ResourceCPU:    resource.MustParse("10m"),
-		},
-	}
-	if cr.Spec.DataSourceConfig != nil && cr.Spec.DataSourceConfig.ProxyResources != nil {
-		resources = *cr.Spec.DataSourceConfig.ProxyResources
-	}
 	container := corev1.Container{
 		Name:            "ds-proxy",
 		Image:           os.Getenv(dsProxyImageEnv),
@@ -48,7 +34,7 @@ func dsProxyContainer(cr *v1alpha1.Grafana) *corev1.Container {
 			"--thanos-address=" + ThanosURL(cr),
 			"--ns-parser-conf=/etc/conf/dsproxy-config.yaml",
 		},
-		Resources: resources,
+		Resources: *Resources(cr).DSProxy,
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      DSProxyConfigSecName,
@@ -0,0 +1,219 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	conf "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/config"
+)
+
+// Keys of the secret referenced by spec.auth.userSync.credentialsSecretRef
+const (
+	IAMAdminUserKey     = "admin_username"
+	IAMAdminPasswordKey = "admin_password"
+)
+
+// Defaults of the login modes
+const (
+	DefaultAuthHeaderName = "X-WEBAUTH-USER"
+	DefaultOIDCName       = "OIDC"
+	DefaultOIDCScopes     = "openid profile email"
+	// LoopbackWhitelist only accepts the auth proxy header from the router
+	// in the grafana pod
+	LoopbackWhitelist = "127.0.0.1, ::1"
+)
+
+// authTemplate is the login configuration rendered into grafana.ini
+type authTemplate struct {
+	Mode              string
+	RootURL           string
+	OIDC              *v1alpha1.OIDCAuth
+	OIDCName          string
+	Scopes            string
+	HeaderName        string
+	HeaderProperty    string
+	Whitelist         string
+	AutoSignUp        bool
+	RoleAttributePath string
+}
+
+// DefaultRoleMapping makes IAM administrators and users allowed to CRUD
+// grafana admins, everybody else is a viewer.
+var DefaultRoleMapping = []v1alpha1.RoleMapping{
+	{IAMRole: "ClusterAdministrator", GrafanaRole: v1alpha1.GrafanaRoleAdmin},
+	{IAMRole: "Administrator", GrafanaRole: v1alpha1.GrafanaRoleAdmin},
+	{Actions: "CRUD", GrafanaRole: v1alpha1.GrafanaRoleAdmin},
+}
+
+// RoleMapping returns the mapping of IAM roles to grafana roles of cr
+func RoleMapping(cr *v1alpha1.Grafana) []v1alpha1.RoleMapping {
+	mapping := DefaultRoleMapping
+	if cr.Spec.Auth != nil && len(cr.Spec.Auth.RoleMapping) > 0 {
+		mapping = cr.Spec.Auth.RoleMapping
+	}
+	out := make([]v1alpha1.RoleMapping, len(mapping))
+	copy(out, mapping)
+	return out
+}
+
+// AuthMode returns how users log in to grafana
+func AuthMode(cr *v1alpha1.Grafana) string {
+	if cr.Spec.Auth == nil || cr.Spec.Auth.Mode == "" {
+		return v1alpha1.AuthModeIAMRouter
+	}
+	return cr.Spec.Auth.Mode
+}
+
+// RouterEnabled is true when the IAM router authenticates the users in front of grafana
+func RouterEnabled(cr *v1alpha1.Grafana) bool {
+	return AuthMode(cr) == v1alpha1.AuthModeIAMRouter
+}
+
+// IngressEnabled is false in the proxy-header mode. Grafana trusts the user
+// header there, it must only be reached through the authenticating proxy.
+func IngressEnabled(cr *v1alpha1.Grafana) bool {
+	return AuthMode(cr) != v1alpha1.AuthModeProxyHeader
+}
+
+// ServiceTargetPort is the pod port behind the grafana service,
+// the router one or grafana itself when there is no router.
+func ServiceTargetPort(cr *v1alpha1.Grafana) int32 {
+	if RouterEnabled(cr) {
+		return DefaultRouterServicePort
+	}
+	return ClusterPort(cr)
+}
+
+func getAuthTemplate(cr *v1alpha1.Grafana) authTemplate {
+	auth := authTemplate{
+		Mode:           AuthMode(cr),
+		HeaderName:     DefaultAuthHeaderName,
+		HeaderProperty: "username",
+		Whitelist:      LoopbackWhitelist,
+	}
+	if cr.Spec.Auth == nil {
+		return auth
+	}
+	auth.RootURL = cr.Spec.Auth.RootURL
+
+	switch auth.Mode {
+	case v1alpha1.AuthModeOIDC:
+		oidc := cr.Spec.Auth.OIDC
+		if oidc == nil {
+			return auth
+		}
+		auth.OIDC = oidc
+		auth.OIDCName = DefaultOIDCName
+		if oidc.Name != "" {
+			auth.OIDCName = oidc.Name
+		}
+		auth.Scopes = DefaultOIDCScopes
+		if len(oidc.Scopes) > 0 {
+			auth.Scopes = strings.Join(oidc.Scopes, " ")
+		}
+	case v1alpha1.AuthModeProxyHeader:
+		// Users are created on their first login, there is no router to create them
+		auth.AutoSignUp = true
+		proxy := cr.Spec.Auth.ProxyHeader
+		if proxy == nil {
+			return auth
+		}
+		if proxy.HeaderName != "" {
+			auth.HeaderName = proxy.HeaderName
+		}
+		if proxy.HeaderProperty != "" {
+			auth.HeaderProperty = proxy.HeaderProperty
+		}
+		// An empty whitelist would accept the header from anybody
+		if len(proxy.Whitelist) > 0 {
+			auth.Whitelist = strings.Join(proxy.Whitelist, ", ")
+		}
+	}
+	return auth
+}
+
+// getAuthEnv passes the OIDC client secret to grafana
+// without writing it into the grafana.ini configmap.
+func getAuthEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
+	if AuthMode(cr) != v1alpha1.AuthModeOIDC || cr.Spec.Auth.OIDC == nil || cr.Spec.Auth.OIDC.ClientSecretRef == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name: "GF_AUTH_GENERIC_OAUTH_CLIENT_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: cr.Spec.Auth.OIDC.ClientSecretRef.DeepCopy(),
+			},
+		},
+	}
+}
+
+// UserSyncInterval returns how often the IAM users are synced into grafana
+func UserSyncInterval(cr *v1alpha1.Grafana) time.Duration {
+	if cr.Spec.Auth == nil || cr.Spec.Auth.UserSync == nil || cr.Spec.Auth.UserSync.Interval == nil ||
+		cr.Spec.Auth.UserSync.Interval.Duration <= 0 {
+		return DefaultUserSyncInterval
+	}
+	return cr.Spec.Auth.UserSync.Interval.Duration
+}
+
+// IAMCredentialsSecretName is the name of the secret with the IAM admin credentials
+func IAMCredentialsSecretName(cr *v1alpha1.Grafana) string {
+	if cr.Spec.Auth != nil && cr.Spec.Auth.UserSync != nil && cr.Spec.Auth.UserSync.CredentialsSecretRef != nil &&
+		cr.Spec.Auth.UserSync.CredentialsSecretRef.Name != "" {
+		return cr.Spec.Auth.UserSync.CredentialsSecretRef.Name
+	}
+	return DefaultIAMCredentialsSecretName
+}
+
+// IAMProviderURL is the URL of the IAM identity provider the router calls
+func IAMProviderURL(cr *v1alpha1.Grafana) string {
+	port := conf.GetControllerConfig().GetConfigString(conf.IAMServicePortName, conf.IAMServicePort)
+	return "https://platform-identity-provider." + cr.Namespace + ".svc." + ClusterDomain + ":" + port
+}
+
+// IAMManagementURL is the URL of the IAM identity management the router calls
+func IAMManagementURL(cr *v1alpha1.Grafana) string {
+	return "https://platform-identity-management." + cr.Namespace + ".svc." + ClusterDomain + ":" + IAMManagementPort
+}
+
+// StaleUserCleanupEnabled is true when the operator removes the grafana users
+// which are no longer IAM users. The users only come from IAM in the iam-router mode.
+func StaleUserCleanupEnabled(cr *v1alpha1.Grafana) bool {
+	return RouterEnabled(cr) && cr.Spec.Auth != nil && cr.Spec.Auth.StaleUserCleanup != nil
+}
+
+// StaleUserCleanupInterval is the interval between two cleanups of the stale users
+func StaleUserCleanupInterval(cr *v1alpha1.Grafana) time.Duration {
+	if !StaleUserCleanupEnabled(cr) || cr.Spec.Auth.StaleUserCleanup.Interval == nil ||
+		cr.Spec.Auth.StaleUserCleanup.Interval.Duration <= 0 {
+		return DefaultStaleUserCleanupInterval
+	}
+	return cr.Spec.Auth.StaleUserCleanup.Interval.Duration
+}
+
+// StaleUserAction is what is done to the stale users, delete or disable
+func StaleUserAction(cr *v1alpha1.Grafana) string {
+	if !StaleUserCleanupEnabled(cr) || cr.Spec.Auth.StaleUserCleanup.Action == "" {
+		return v1alpha1.StaleUserDelete
+	}
+	return cr.Spec.Auth.StaleUserCleanup.Action
+}
@@ -0,0 +1,196 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// DefaultTargetCPUUtilization is the CPU target of an HPA without targets
+const DefaultTargetCPUUtilization int32 = 80
+
+// HorizontalPodAutoscalerGroupKind is the kind of the HPA. It is served as
+// autoscaling/v2 since kubernetes 1.23, autoscaling/v2beta2 was removed in 1.26.
+var HorizontalPodAutoscalerGroupKind = schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}
+
+// HorizontalPodAutoscalerVersions are the HPA versions the operator supports, newest first
+var HorizontalPodAutoscalerVersions = []string{"v2", "v2beta2"}
+
+// VerticalPodAutoscalerGVK is the kind of the VPA. Its types are not part of
+// the kubernetes API, the VPA is handled as an unstructured object.
+var VerticalPodAutoscalerGVK = schema.GroupVersionKind{
+	Group:   "autoscaling.k8s.io",
+	Version: "v1",
+	Kind:    "VerticalPodAutoscaler",
+}
+
+// HorizontalAutoscalingEnabled is true when an HPA scales the grafana deployment
+func HorizontalAutoscalingEnabled(cr *v1alpha1.Grafana) bool {
+	return cr.Spec.Autoscaling != nil && cr.Spec.Autoscaling.HPA != nil
+}
+
+// VerticalAutoscalingEnabled is true when a VPA watches the grafana deployment
+func VerticalAutoscalingEnabled(cr *v1alpha1.Grafana) bool {
+	return cr.Spec.Autoscaling != nil && cr.Spec.Autoscaling.VPA != nil
+}
+
+// VPAMode returns the mode of the VPA, recommend by default
+func VPAMode(cr *v1alpha1.Grafana) string {
+	if !VerticalAutoscalingEnabled(cr) || cr.Spec.Autoscaling.VPA.Mode == "" {
+		return v1alpha1.VPAModeRecommend
+	}
+	return cr.Spec.Autoscaling.VPA.Mode
+}
+
+// MinReplicas returns the lowest number of grafana pods
+func MinReplicas(cr *v1alpha1.Grafana) int32 {
+	if !HorizontalAutoscalingEnabled(cr) {
+		return Replicas(cr)
+	}
+	if min := cr.Spec.Autoscaling.HPA.MinReplicas; min != nil {
+		return *min
+	}
+	return 1
+}
+
+// MaxReplicas returns the highest number of grafana pods
+func MaxReplicas(cr *v1alpha1.Grafana) int32 {
+	if !HorizontalAutoscalingEnabled(cr) {
+		return Replicas(cr)
+	}
+	return cr.Spec.Autoscaling.HPA.MaxReplicas
+}
+
+// AutoscalerName is the name of the HPA and the VPA of cr
+func AutoscalerName(cr *v1alpha1.Grafana) string {
+	return DeploymentName(cr)
+}
+
+func autoscalerLabels() map[string]string {
+	return appendCommonLabels(map[string]string{"app": "grafana", "component": "grafana"})
+}
+
+func utilizationMetric(name corev1.ResourceName, target int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &target,
+			},
+		},
+	}
+}
+
+func getHorizontalPodAutoscalerSpec(cr *v1alpha1.Grafana) autoscalingv2beta2.HorizontalPodAutoscalerSpec {
+	hpaSpec := autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       DeploymentName(cr),
+		},
+	}
+	if !HorizontalAutoscalingEnabled(cr) {
+		return hpaSpec
+	}
+
+	spec := cr.Spec.Autoscaling.HPA
+	minReplicas := MinReplicas(cr)
+	hpaSpec.MinReplicas = &minReplicas
+	hpaSpec.MaxReplicas = spec.MaxReplicas
+	if spec.TargetCPUUtilization != nil {
+		hpaSpec.Metrics = append(hpaSpec.Metrics, utilizationMetric(corev1.ResourceCPU, *spec.TargetCPUUtilization))
+	}
+	if spec.TargetMemoryUtilization != nil {
+		hpaSpec.Metrics = append(hpaSpec.Metrics, utilizationMetric(corev1.ResourceMemory, *spec.TargetMemoryUtilization))
+	}
+	if len(hpaSpec.Metrics) == 0 {
+		hpaSpec.Metrics = []autoscalingv2beta2.MetricSpec{utilizationMetric(corev1.ResourceCPU, DefaultTargetCPUUtilization)}
+	}
+	return hpaSpec
+}
+
+// GrafanaHorizontalPodAutoscaler scales the grafana deployment on the CPU
+// and memory usage of its pods. The HPA is built in the version gvk the
+// cluster serves, autoscaling/v2 and v2beta2 share the fields set here.
+func GrafanaHorizontalPodAutoscaler(cr *v1alpha1.Grafana, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	spec := getHorizontalPodAutoscalerSpec(cr)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return nil, err
+	}
+	hpa := &unstructured.Unstructured{Object: map[string]interface{}{"spec": content}}
+	hpa.SetGroupVersionKind(gvk)
+	hpa.SetName(AutoscalerName(cr))
+	hpa.SetNamespace(cr.Namespace)
+	hpa.SetLabels(autoscalerLabels())
+	return hpa, nil
+}
+
+// resourceListContent converts resources to their unstructured form
+func resourceListContent(resources corev1.ResourceList) map[string]interface{} {
+	content := map[string]interface{}{}
+	for name, quantity := range resources {
+		content[string(name)] = quantity.String()
+	}
+	return content
+}
+
+// GrafanaVerticalPodAutoscaler sizes the containers of the grafana deployment.
+// The recommend mode maps to the Off update mode of the VPA.
+func GrafanaVerticalPodAutoscaler(cr *v1alpha1.Grafana) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{}
+	vpa.SetGroupVersionKind(VerticalPodAutoscalerGVK)
+	vpa.SetName(AutoscalerName(cr))
+	vpa.SetNamespace(cr.Namespace)
+	vpa.SetLabels(autoscalerLabels())
+	if !VerticalAutoscalingEnabled(cr) {
+		return vpa
+	}
+
+	updateMode := "Off"
+	if VPAMode(cr) == v1alpha1.VPAModeAuto {
+		updateMode = "Auto"
+	}
+	policy := map[string]interface{}{"containerName": "*"}
+	if min := cr.Spec.Autoscaling.VPA.MinAllowed; len(min) != 0 {
+		policy["minAllowed"] = resourceListContent(min)
+	}
+	if max := cr.Spec.Autoscaling.VPA.MaxAllowed; len(max) != 0 {
+		policy["maxAllowed"] = resourceListContent(max)
+	}
+	vpa.Object["spec"] = map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       DeploymentName(cr),
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": updateMode,
+		},
+		"resourcePolicy": map[string]interface{}{
+			"containerPolicies": []interface{}{policy},
+		},
+	}
+	return vpa
+}
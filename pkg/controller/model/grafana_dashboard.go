@@ -59,7 +59,7 @@ func setupDashboardEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
 	prometheusHost, prometheusPort = prometheusInfo(cr)
 
 	envs := []corev1.EnvVar{}
-	envs = append(envs, setupAdminEnv("USER", "PASSWORD")...)
+	envs = append(envs, setupAdminEnv(cr, "USER", "PASSWORD")...)
 	if cr.Spec.IsHub {
 		isHub = true
 	} else {
@@ -108,18 +108,12 @@ func setupDashboardEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
 
 func createDashboardContainer(cr *v1alpha1.Grafana) corev1.Container {
 
-	var resources corev1.ResourceRequirements
 	image := imageName(os.Getenv(dashboardCtlImageEnv), cr.Spec.DashboardControllerImage)
-	if cr.Spec.DashboardsConfig != nil && cr.Spec.DashboardsConfig.Resources != nil {
-		resources = *cr.Spec.DashboardsConfig.Resources
-	} else {
-		resources = getContainerResource(cr, "Dashboard")
-	}
 	return corev1.Container{
 		Name:                     "dashboard-controller",
 		Image:                    image,
 		ImagePullPolicy:          "IfNotPresent",
-		Resources:                resources,
+		Resources:                *Resources(cr).Dashboard,
 		LivenessProbe:            getProbe(40, 30, 10),
 		ReadinessProbe:           getProbe(30, 30, 10),
 		Command:                  []string{"/grafana/entry/run.sh"},
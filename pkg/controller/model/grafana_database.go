@@ -0,0 +1,99 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// Keys of the secret referenced by spec.database.secretRef
+const (
+	DatabaseUserKey     = "username"
+	DatabasePasswordKey = "password"
+)
+
+// DatabaseType returns the type of the database storing the grafana state
+func DatabaseType(cr *v1alpha1.Grafana) string {
+	if cr.Spec.Database == nil || cr.Spec.Database.Type == "" {
+		return v1alpha1.DatabaseSQLite
+	}
+	return cr.Spec.Database.Type
+}
+
+// ExternalDatabase is true when the grafana state is in a mysql or postgres
+// server shared by all the replicas.
+func ExternalDatabase(cr *v1alpha1.Grafana) bool {
+	dbType := DatabaseType(cr)
+	return dbType == v1alpha1.DatabaseMySQL || dbType == v1alpha1.DatabasePostgres
+}
+
+// DatabaseAddress returns the host:port of the external database,
+// with the default port of its type when the host has none.
+func DatabaseAddress(cr *v1alpha1.Grafana) string {
+	host := cr.Spec.Database.Host
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	port := "3306"
+	if DatabaseType(cr) == v1alpha1.DatabasePostgres {
+		port = "5432"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// Replicas returns the number of grafana pods. With an HPA it is the number
+// the deployment is created with, the HPA owns it afterwards.
+func Replicas(cr *v1alpha1.Grafana) int32 {
+	if HorizontalAutoscalingEnabled(cr) {
+		return MinReplicas(cr)
+	}
+	if cr.Spec.Replicas == nil {
+		return 1
+	}
+	return *cr.Spec.Replicas
+}
+
+// DatabaseSecretNames returns the secret holding the database credentials of cr, if any
+func DatabaseSecretNames(cr *v1alpha1.Grafana) []string {
+	if !ExternalDatabase(cr) || cr.Spec.Database.SecretRef == nil || cr.Spec.Database.SecretRef.Name == "" {
+		return nil
+	}
+	return []string{cr.Spec.Database.SecretRef.Name}
+}
+
+// getDatabaseEnv passes the database credentials to grafana
+// without writing them into the grafana.ini configmap.
+func getDatabaseEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
+	if !ExternalDatabase(cr) || cr.Spec.Database.SecretRef == nil {
+		return nil
+	}
+	secretKeyRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: *cr.Spec.Database.SecretRef,
+				Key:                  key,
+			},
+		}
+	}
+	return []corev1.EnvVar{
+		{Name: "GF_DATABASE_USER", ValueFrom: secretKeyRef(DatabaseUserKey)},
+		{Name: "GF_DATABASE_PASSWORD", ValueFrom: secretKeyRef(DatabasePasswordKey)},
+	}
+}
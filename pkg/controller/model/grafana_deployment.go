@@ -32,8 +32,7 @@ func getPersistentVolume(cr *v1alpha1.Grafana, name string) corev1.Volume {
 		Name: name,
 		VolumeSource: corev1.VolumeSource{
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-				ClaimName: cr.Spec.PersistentVolume.ClaimName,
-				ReadOnly:  true,
+				ClaimName: PersistentVolumeClaimName(cr),
 			},
 		},
 	}
@@ -71,7 +70,7 @@ func getVolumes(cr *v1alpha1.Grafana) []corev1.Volume {
 		},
 	)
 
-	if cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.Enabled {
+	if PersistentVolumeEnabled(cr) {
 		storageVol := getPersistentVolume(cr, "grafana-storage")
 		volumes = append(volumes, storageVol)
 	} else {
@@ -84,36 +83,40 @@ func getVolumes(cr *v1alpha1.Grafana) []corev1.Volume {
 	}
 
 	// configmap name also the volume name
-	volumes = append(volumes, createVolumeFromCM(GrafanaConfigName),
-		createVolumeFromCM(dsConfig),
-		createVolumeFromCM(grafanaDBConfig),
-		createVolumeFromCM(grafanaDefaultDashboard),
-		createVolumeFromCM(grafanaCRD),
-		createVolumeFromCM(routerConfig),
-		createVolumeFromCM(routerEntry),
-		createVolumeFromCM(grafanaLua),
-		createVolumeFromCM(utilLua),
+	volumes = append(volumes, createVolumeFromCM(cr, GrafanaConfigName),
+		createVolumeFromCM(cr, dsConfig),
+		createVolumeFromCM(cr, grafanaDBConfig),
+		createVolumeFromCM(cr, grafanaDefaultDashboard),
+		createVolumeFromCM(cr, grafanaCRD),
+		createVolumeFromCM(cr, routerConfig),
+		createVolumeFromCM(cr, routerEntry),
+		createVolumeFromCM(cr, grafanaLua),
+		createVolumeFromCM(cr, utilLua),
 	)
-
-	var cert, clientCert string
-	if cr.Spec.TLSSecretName != "" && cr.Spec.TLSClientSecretName != "" {
-		cert = cr.Spec.TLSSecretName
+	// The orgs are only listed once the operator synced the users
+	orgs := createVolumeFromCM(cr, grafanaOrgs)
+	orgs.ConfigMap.Optional = boolPtr(true)
+	volumes = append(volumes, orgs)
+
+	cert := CertSecretName(cr)
+	clientCert := cert
+	if cr.Spec.TLSClientSecretName != "" {
 		clientCert = cr.Spec.TLSClientSecretName
-	} else {
-		cert = "ibm-monitoring-certs"
-		clientCert = "ibm-monitoring-certs"
 	}
 
 	volumes = append(volumes, createVolumeFromSecret(cert, "ibm-monitoring-ca-certs"),
 		createVolumeFromSecret(cert, "ibm-monitoring-certs"),
 		createVolumeFromSecret(clientCert, "ibm-monitoring-client-certs"),
-		createVolumeFromSecret(DSProxyConfigSecName, DSProxyConfigSecName),
+		createVolumeFromSecret(DSProxyConfigSecretName(cr), DSProxyConfigSecName),
 	)
+	bundles, _, _ := getPluginBundles(cr)
+	volumes = append(volumes, bundles...)
+	volumes = append(volumes, getSMTPCertVolume(cr)...)
 
 	return volumes
 }
 
-func getVolumeMounts() []corev1.VolumeMount {
+func getVolumeMounts(cr *v1alpha1.Grafana) []corev1.VolumeMount {
 	var mounts []corev1.VolumeMount
 
 	mounts = append(mounts,
@@ -147,6 +150,8 @@ func getVolumeMounts() []corev1.VolumeMount {
 			MountPath: "/opt/ibm/monitoring/certs",
 		},
 	)
+	mounts = append(mounts, getPluginVolumeMount(cr)...)
+	mounts = append(mounts, getSMTPCertVolumeMount(cr)...)
 
 	return mounts
 }
@@ -166,19 +171,17 @@ func getProbe(delay, timeout, failure int32) *corev1.Probe {
 		InitialDelaySeconds: delay,
 		TimeoutSeconds:      timeout,
 		FailureThreshold:    failure,
+		// Set the apiserver defaults explicitly so the live deployment
+		// compares equal to the desired one.
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
 	}
 }
 
 func getContainers(cr *v1alpha1.Grafana) []corev1.Container {
 
-	var resources corev1.ResourceRequirements
 	containers := []corev1.Container{}
 	image := imageName(os.Getenv(grafanaImageEnv), cr.Spec.BaseImage)
-	if cr.Spec.GrafanaConfig != nil && cr.Spec.GrafanaConfig.Resources != nil {
-		resources = *cr.Spec.GrafanaConfig.Resources
-	} else {
-		resources = getContainerResource(cr, "Grafana")
-	}
 
 	containers = append(containers,
 		corev1.Container{
@@ -191,28 +194,38 @@ func getContainers(cr *v1alpha1.Grafana) []corev1.Container {
 					Protocol:      "TCP",
 				},
 			},
-			Resources:                resources,
-			VolumeMounts:             getVolumeMounts(),
+			Resources:                *Resources(cr).Grafana,
+			Env:                      getGrafanaEnv(cr),
+			VolumeMounts:             getVolumeMounts(cr),
 			LivenessProbe:            getProbe(40, 35, 15),
 			ReadinessProbe:           getProbe(30, 30, 10),
 			TerminationMessagePath:   "/dev/termination-log",
 			TerminationMessagePolicy: "File",
 			ImagePullPolicy:          "IfNotPresent",
 		},
-		createRouterContainer(cr),
+	)
+	// Without the router grafana authenticates the users itself
+	if RouterEnabled(cr) {
+		containers = append(containers, createRouterContainer(cr))
+	}
+	containers = append(containers,
 		createDashboardContainer(cr),
 		*dsProxyContainer(cr),
 	)
 	return containers
 }
 
+func getGrafanaEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
+	env := setupAdminEnv(cr, "GF_SECURITY_ADMIN_USER", "GF_SECURITY_ADMIN_PASSWORD")
+	env = append(env, getDatabaseEnv(cr)...)
+	env = append(env, getSMTPEnv(cr)...)
+	return append(env, getAuthEnv(cr)...)
+}
+
 func getPodLabels(cr *v1alpha1.Grafana) map[string]string {
 
-	labels := map[string]string{
-		"app":       "grafana",
-		"component": "grafana",
-		"intent":    "projected",
-	}
+	labels := InstanceSelector(cr)
+	labels["intent"] = "projected"
 	labels = appendCommonLabels(labels)
 	if cr.Spec.Service != nil && cr.Spec.Service.Labels != nil {
 		mergeMaps(labels, cr.Spec.Service.Labels)
@@ -282,26 +295,76 @@ func getInitContainers(cr *v1alpha1.Grafana) []corev1.Container {
 		},
 	)
 
-	return []corev1.Container{
+	containers := []corev1.Container{
 		{
 			Name:            InitContainerName,
 			Image:           image,
 			Command:         []string{"/opt/entry/entrypoint.sh"},
-			Resources:       corev1.ResourceRequirements{},
+			Resources:       *Resources(cr).Init,
 			VolumeMounts:    volumeMounts,
 			ImagePullPolicy: "IfNotPresent",
 		},
 	}
+	if PluginsEnabled(cr) {
+		containers = append(containers, createPluginInstallContainer(cr))
+	}
+	return containers
 }
 
-func getDeploymentSpec(cr *v1alpha1.Grafana) appv1.DeploymentSpec {
-
-	selectors := metav1.LabelSelector{
-		MatchLabels: map[string]string{
-			"app":       "grafana",
-			"component": "grafana",
+// getAffinity returns the affinity of the spec, and spreads the replicas of
+// a highly available grafana over the nodes when it sets no pod anti affinity.
+func getAffinity(cr *v1alpha1.Grafana) *corev1.Affinity {
+	var affinity *corev1.Affinity
+	if cr.Spec.Affinity != nil {
+		affinity = cr.Spec.Affinity.DeepCopy()
+	}
+	if MaxReplicas(cr) <= 1 || (affinity != nil && affinity.PodAntiAffinity != nil) {
+		return affinity
+	}
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+			{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: InstanceSelector(cr),
+					},
+					TopologyKey: "kubernetes.io/hostname",
+				},
+			},
 		},
 	}
+	return affinity
+}
+
+// getTopologySpreadConstraints returns the constraints of the spec, those
+// without a label selector count the pods of cr.
+func getTopologySpreadConstraints(cr *v1alpha1.Grafana) []corev1.TopologySpreadConstraint {
+	if len(cr.Spec.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	constraints := make([]corev1.TopologySpreadConstraint, 0, len(cr.Spec.TopologySpreadConstraints))
+	for _, c := range cr.Spec.TopologySpreadConstraints {
+		constraint := *c.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: InstanceSelector(cr)}
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+// deploymentSelector selects the pods of the grafana deployment of cr
+func deploymentSelector(cr *v1alpha1.Grafana) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: InstanceSelector(cr),
+	}
+}
+
+func getDeploymentSpec(cr *v1alpha1.Grafana) appv1.DeploymentSpec {
 
 	var serviceAccount string
 	if cr.Spec.ServiceAccount != "" {
@@ -310,28 +373,35 @@ func getDeploymentSpec(cr *v1alpha1.Grafana) appv1.DeploymentSpec {
 		serviceAccount = GrafanaServiceAccountName
 	}
 
-	// Do not support multiple instance now for 1st release.
-	var replicas int32 = 1
+	podSpec := corev1.PodSpec{
+		ImagePullSecrets:   getImagePullSecrets(cr),
+		InitContainers:     getInitContainers(cr),
+		HostPID:            false,
+		HostIPC:            false,
+		HostNetwork:        false,
+		Volumes:            getVolumes(cr),
+		Containers:         getContainers(cr),
+		ServiceAccountName: serviceAccount,
+		NodeSelector:       cr.Spec.NodeSelector,
+		Affinity:           getAffinity(cr),
+		Tolerations:        cr.Spec.Tolerations,
+		PriorityClassName:  cr.Spec.PriorityClassName,
+
+		TopologySpreadConstraints: getTopologySpreadConstraints(cr),
+	}
+	applySecurityProfile(cr, &podSpec)
+
+	replicas := Replicas(cr)
 	return appv1.DeploymentSpec{
 		Replicas: &replicas,
-		Selector: &selectors,
+		Selector: deploymentSelector(cr),
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        GrafanaDeploymentName,
+				Name:        DeploymentName(cr),
 				Labels:      getPodLabels(cr),
 				Annotations: getPodAnnotations(cr),
 			},
-			Spec: corev1.PodSpec{
-				ImagePullSecrets:   getImagePullSecrets(cr),
-				InitContainers:     getInitContainers(cr),
-				HostPID:            false,
-				HostIPC:            false,
-				HostNetwork:        false,
-				Volumes:            getVolumes(cr),
-				Containers:         getContainers(cr),
-				ServiceAccountName: serviceAccount,
-				NodeSelector:       cr.Spec.NodeSelector,
-			},
+			Spec: podSpec,
 		},
 	}
 }
@@ -339,7 +409,7 @@ func getDeploymentSpec(cr *v1alpha1.Grafana) appv1.DeploymentSpec {
 func GrafanaDeployment(cr *v1alpha1.Grafana) *appv1.Deployment {
 	return &appv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      GrafanaDeploymentName,
+			Name:      DeploymentName(cr),
 			Namespace: cr.Namespace,
 		},
 		Spec: getDeploymentSpec(cr),
@@ -349,7 +419,7 @@ func GrafanaDeployment(cr *v1alpha1.Grafana) *appv1.Deployment {
 func GrafanaDeploymentSelector(cr *v1alpha1.Grafana) client.ObjectKey {
 
 	return client.ObjectKey{
-		Name:      GrafanaDeploymentName,
+		Name:      DeploymentName(cr),
 		Namespace: cr.ObjectMeta.Namespace,
 	}
 }
@@ -358,6 +428,10 @@ func ReconciledGrafanaDeployment(cr *v1alpha1.Grafana, current *appv1.Deployment
 	reconciled := current.DeepCopy()
 	spec := getDeploymentSpec(cr)
 	reconciled.Spec = spec
+	// The HPA scales the deployment, its replica count is left alone
+	if HorizontalAutoscalingEnabled(cr) && current.Spec.Replicas != nil {
+		reconciled.Spec.Replicas = current.Spec.Replicas
+	}
 
 	return reconciled
 }
@@ -23,8 +23,28 @@ import (
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
 )
 
+// GrafanaIngressName is the ingress name of the single instance releases
 var GrafanaIngressName string = "grafana-ingress"
 
+// LegacyIngressPath is the ingress path of the single instance releases
+const LegacyIngressPath = "/grafana"
+
+// IngressPath is the path grafana is served under. The CR of the single
+// instance releases keeps /grafana, the links to it still work, the other
+// CRs get a path of their own.
+func IngressPath(cr *v1alpha1.Grafana) string {
+	if DeploymentName(cr) == GrafanaDeploymentName {
+		return LegacyIngressPath
+	}
+	return LegacyIngressPath + "-" + cr.Name
+}
+
+// ServeFromSubPath is true when the requests reach grafana with the ingress
+// path. The IBM management ingress strips it in front of the router.
+func ServeFromSubPath(cr *v1alpha1.Grafana) bool {
+	return IngressEnabled(cr) && !RouterEnabled(cr)
+}
+
 func GetIngressLabels(cr *v1alpha1.Grafana) map[string]string {
 
 	labels := map[string]string{
@@ -38,22 +58,28 @@ func GetIngressLabels(cr *v1alpha1.Grafana) map[string]string {
 	return labels
 }
 
+// GetIngressAnnotations returns the annotations of the ingress. The IBM
+// management ingress only fronts the IAM router, in the other login modes
+// the ingress class and its settings come from spec.service.annotations.
 func GetIngressAnnotations(cr *v1alpha1.Grafana) map[string]string {
-	annotations := map[string]string{
-		"kubernetes.io/ingress.class":                    "ibm-icp-management",
-		"icp.management.ibm.com/authz-type":              "rbac",
-		"icp.management.ibm.com/secure-backends":         "true",
-		"icp.management.ibm.com/secure-client-ca-secret": cr.Spec.TLSClientSecretName,
-		"icp.management.ibm.com/rewrite-target":          "/",
+	annotations := map[string]string{}
+	if RouterEnabled(cr) {
+		annotations = map[string]string{
+			"kubernetes.io/ingress.class":                    "ibm-icp-management",
+			"icp.management.ibm.com/authz-type":              "rbac",
+			"icp.management.ibm.com/secure-backends":         "true",
+			"icp.management.ibm.com/secure-client-ca-secret": cr.Spec.TLSClientSecretName,
+			"icp.management.ibm.com/rewrite-target":          "/",
+		}
 	}
 
-	if cr.Spec.Service.Annotations != nil && len(cr.Spec.Service.Annotations) != 0 {
+	if cr.Spec.Service != nil && len(cr.Spec.Service.Annotations) != 0 {
 		mergeMaps(annotations, cr.Spec.Service.Annotations)
 	}
 	return annotations
 }
 
-func getIngressSpec() ingressv1.IngressSpec {
+func getIngressSpec(cr *v1alpha1.Grafana) ingressv1.IngressSpec {
 	pathType := ingressv1.PathType("ImplementationSpecific")
 	return ingressv1.IngressSpec{
 		Rules: []ingressv1.IngressRule{
@@ -62,11 +88,11 @@ func getIngressSpec() ingressv1.IngressSpec {
 					HTTP: &ingressv1.HTTPIngressRuleValue{
 						Paths: []ingressv1.HTTPIngressPath{
 							{
-								Path:     "/grafana",
+								Path:     IngressPath(cr),
 								PathType: &pathType,
 								Backend: ingressv1.IngressBackend{
 									Service: &ingressv1.IngressServiceBackend{
-										Name: GrafanaServiceName,
+										Name: ServiceName(cr),
 										Port: ingressv1.ServiceBackendPort{
 											Number: DefaultGrafanaPort,
 										},
@@ -84,19 +110,19 @@ func getIngressSpec() ingressv1.IngressSpec {
 func GrafanaIngress(cr *v1alpha1.Grafana) *ingressv1.Ingress {
 	return &ingressv1.Ingress{
 		ObjectMeta: v1.ObjectMeta{
-			Name:        GrafanaIngressName,
+			Name:        IngressName(cr),
 			Namespace:   cr.Namespace,
 			Labels:      GetIngressLabels(cr),
 			Annotations: GetIngressAnnotations(cr),
 		},
-		Spec: getIngressSpec(),
+		Spec: getIngressSpec(cr),
 	}
 }
 
 func ReconciledGrafanaIngress(cr *v1alpha1.Grafana, current *ingressv1.Ingress) *ingressv1.Ingress {
 
 	reconciled := current.DeepCopy()
-	spec := getIngressSpec()
+	spec := getIngressSpec(cr)
 	reconciled.Spec = spec
 	reconciled.Labels = GetIngressLabels(cr)
 	reconciled.Annotations = GetIngressAnnotations(cr)
@@ -106,6 +132,6 @@ func ReconciledGrafanaIngress(cr *v1alpha1.Grafana, current *ingressv1.Ingress)
 func GrafanaIngressSelector(cr *v1alpha1.Grafana) client.ObjectKey {
 	return client.ObjectKey{
 		Namespace: cr.Namespace,
-		Name:      GrafanaIngressName,
+		Name:      IngressName(cr),
 	}
 }
@@ -0,0 +1,139 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// protectedINIKeys are the grafana.ini keys the operator renders from the
+// spec or the pod layout, or which hold a secret passed through the environment.
+var protectedINIKeys = map[string][]string{
+	"paths":              {"data", "logs", "plugins"},
+	"plugins":            {"allow_loading_unsigned_plugins"},
+	"server":             {"protocol", "http_port", "cert_file", "cert_key"},
+	"security":           {"admin_user", "admin_password"},
+	"auth.proxy":         {"enabled", "header_name", "header_property"},
+	"auth.generic_oauth": {"client_secret"},
+	"smtp":               {"user", "password", "cert_file", "key_file"},
+	"database":           {"type", "host", "name", "user", "password"},
+}
+
+// ProtectedINIKey is true when the key of a grafana.ini section can not be
+// set through spec.grafanaConfig.ini
+func ProtectedINIKey(section, key string) bool {
+	for _, protected := range protectedINIKeys[section] {
+		if key == protected {
+			return true
+		}
+	}
+	return false
+}
+
+// INIOverrides returns the grafana.ini sections set in the spec
+func INIOverrides(cr *v1alpha1.Grafana) map[string]v1alpha1.INISection {
+	if cr.Spec.GrafanaConfig == nil {
+		return nil
+	}
+	return cr.Spec.GrafanaConfig.INI
+}
+
+// mergeINI sets the keys of overrides in an ini file. A key already in its
+// section is replaced in place, the other keys are added at the end of their
+// section and the missing sections at the end of the file.
+func mergeINI(ini string, overrides map[string]v1alpha1.INISection) string {
+	if len(overrides) == 0 {
+		return ini
+	}
+	pending := map[string]map[string]string{}
+	for section, keys := range overrides {
+		pending[section] = map[string]string{}
+		for key, value := range keys {
+			pending[section][key] = value
+		}
+	}
+
+	out := []string{}
+	// insert adds lines after the last non empty line, so that the added
+	// keys stay before the blank line separating two sections
+	insert := func(lines ...string) {
+		end := len(out)
+		for end > 0 && strings.TrimSpace(out[end-1]) == "" {
+			end--
+		}
+		tail := append([]string{}, out[end:]...)
+		out = append(append(out[:end], lines...), tail...)
+	}
+	iniLine := func(indent, key, value string) string {
+		return indent + key + " = " + value
+	}
+	flush := func(section, indent string) {
+		keys := pending[section]
+		delete(pending, section)
+		for _, key := range sortedKeys(keys) {
+			insert(iniLine(indent, key, keys[key]))
+		}
+	}
+
+	section, indent, firstIndent := "", "", ""
+	for _, line := range strings.Split(ini, "\n") {
+		trimmed := strings.TrimSpace(line)
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		switch {
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			flush(section, indent)
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			indent = lineIndent
+			if firstIndent == "" {
+				firstIndent = indent
+			}
+		case trimmed != "" && !strings.HasPrefix(trimmed, ";") && !strings.HasPrefix(trimmed, "#") &&
+			strings.Contains(trimmed, "="):
+			key := strings.TrimSpace(trimmed[:strings.Index(trimmed, "=")])
+			if value, ok := pending[section][key]; ok {
+				line = iniLine(lineIndent, key, value)
+				delete(pending[section], key)
+			}
+		}
+		out = append(out, line)
+	}
+	flush(section, indent)
+
+	sections := []string{}
+	for name := range pending {
+		if len(pending[name]) > 0 {
+			sections = append(sections, name)
+		}
+	}
+	sort.Strings(sections)
+	for _, name := range sections {
+		insert("", firstIndent+"["+name+"]")
+		flush(name, firstIndent)
+	}
+	return strings.Join(out, "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
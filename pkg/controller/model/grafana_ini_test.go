@@ -0,0 +1,89 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestMergeINI(t *testing.T) {
+	tests := []struct {
+		name      string
+		ini       []string
+		overrides map[string]v1alpha1.INISection
+		want      []string
+	}{
+		{
+			name:      "no overrides",
+			ini:       []string{"[log]", "mode = console"},
+			overrides: nil,
+			want:      []string{"[log]", "mode = console"},
+		},
+		{
+			name:      "key replaced in place",
+			ini:       []string{"[log]", "mode = console", "level = info", "", "[users]", "allow_sign_up = false"},
+			overrides: map[string]v1alpha1.INISection{"log": {"mode": "file"}},
+			want:      []string{"[log]", "mode = file", "level = info", "", "[users]", "allow_sign_up = false"},
+		},
+		{
+			name:      "key added before the blank line ending its section",
+			ini:       []string{"[log]", "mode = console", "", "[users]", "allow_sign_up = false"},
+			overrides: map[string]v1alpha1.INISection{"log": {"level": "debug"}},
+			want:      []string{"[log]", "mode = console", "level = debug", "", "[users]", "allow_sign_up = false"},
+		},
+		{
+			name:      "commented key is not replaced",
+			ini:       []string{"[log]", ";level = info", "mode = console"},
+			overrides: map[string]v1alpha1.INISection{"log": {"level": "warn"}},
+			want:      []string{"[log]", ";level = info", "mode = console", "level = warn"},
+		},
+		{
+			name: "missing sections appended in order",
+			ini:  []string{"[log]", "mode = console", ""},
+			overrides: map[string]v1alpha1.INISection{
+				"users":      {"allow_sign_up": "true"},
+				"dashboards": {"versions_to_keep": "5", "min_refresh_interval": "10s"},
+			},
+			want: []string{"[log]", "mode = console", "",
+				"[dashboards]", "min_refresh_interval = 10s", "versions_to_keep = 5", "",
+				"[users]", "allow_sign_up = true", ""},
+		},
+		{
+			name:      "indentation kept",
+			ini:       []string{"    [log]", "    mode = console", "", "    [users]", "    allow_sign_up = false"},
+			overrides: map[string]v1alpha1.INISection{"log": {"mode": "file", "level": "debug"}, "auth": {"disable_login_form": "true"}},
+			want: []string{"    [log]", "    mode = file", "    level = debug", "", "    [users]", "    allow_sign_up = false",
+				"", "    [auth]", "    disable_login_form = true"},
+		},
+		{
+			name:      "keys of the preamble",
+			ini:       []string{"app_mode = production", "", "[log]", "mode = console"},
+			overrides: map[string]v1alpha1.INISection{"": {"app_mode": "development"}},
+			want:      []string{"app_mode = development", "", "[log]", "mode = console"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeINI(strings.Join(tt.ini, "\n"), tt.overrides)
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// PodDisruptionBudgetName is the name of the grafana PodDisruptionBudget of cr
+func PodDisruptionBudgetName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, "grafana")
+}
+
+// getDisruptionBudget returns the minAvailable and maxUnavailable of the
+// budget, one of them is nil. A single pod can be unavailable by default.
+func getDisruptionBudget(cr *v1alpha1.Grafana) (minAvailable, maxUnavailable *intstr.IntOrString) {
+	if config := cr.Spec.PodDisruptionBudget; config != nil {
+		if config.MinAvailable != nil {
+			return config.MinAvailable, nil
+		}
+		if config.MaxUnavailable != nil {
+			return nil, config.MaxUnavailable
+		}
+	}
+	defaultMaxUnavailable := intstr.FromInt(1)
+	return nil, &defaultMaxUnavailable
+}
+
+// DisruptionsAllowed returns the number of grafana pods the budget lets a
+// node drain evict when the deployment runs its lowest number of replicas.
+// Percentages are rounded up, as the disruption controller does.
+func DisruptionsAllowed(cr *v1alpha1.Grafana) (int32, error) {
+	replicas := int(MinReplicas(cr))
+	minAvailable, maxUnavailable := getDisruptionBudget(cr)
+	var allowed int
+	if minAvailable != nil {
+		available, err := intstr.GetScaledValueFromIntOrPercent(minAvailable, replicas, true)
+		if err != nil {
+			return 0, err
+		}
+		allowed = replicas - available
+	} else {
+		unavailable, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, replicas, true)
+		if err != nil {
+			return 0, err
+		}
+		allowed = unavailable
+	}
+	if allowed < 0 {
+		allowed = 0
+	}
+	if allowed > replicas {
+		allowed = replicas
+	}
+	return int32(allowed), nil
+}
+
+// GrafanaPodDisruptionBudget limits the grafana pods evicted at once by node drains
+func GrafanaPodDisruptionBudget(cr *v1alpha1.Grafana) *policyv1.PodDisruptionBudget {
+	labels := map[string]string{"app": "grafana", "component": "grafana"}
+	labels = appendCommonLabels(labels)
+	minAvailable, maxUnavailable := getDisruptionBudget(cr)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PodDisruptionBudgetName(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector:       deploymentSelector(cr),
+		},
+	}
+}
@@ -0,0 +1,140 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+	tpls "github.com/IBM/ibm-monitoring-grafana-operator/pkg/controller/artifacts"
+)
+
+const (
+	// PluginInstallContainerName is the init container installing spec.plugins
+	PluginInstallContainerName = "install-plugins"
+	// PluginsDir is where grafana loads the plugins from
+	PluginsDir = "/var/lib/grafana/plugins"
+	// pluginBundlesDir is where the configmaps and claims holding plugin zips are mounted
+	pluginBundlesDir = "/opt/plugin-bundles"
+)
+
+// pluginTemplate is a plugin as installed by grafana-cli, URL is
+// the path of the zip when it comes from a configmap or a claim
+type pluginTemplate struct {
+	Name    string
+	Version string
+	URL     string
+}
+
+// PluginsEnabled is true when the operator installs plugins
+func PluginsEnabled(cr *v1alpha1.Grafana) bool {
+	return len(cr.Spec.Plugins) > 0
+}
+
+// UnsignedPlugins lists the plugins grafana may load without a valid signature
+func UnsignedPlugins(cr *v1alpha1.Grafana) string {
+	names := []string{}
+	for _, plugin := range cr.Spec.Plugins {
+		if plugin.AllowUnsigned {
+			names = append(names, plugin.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// getPluginBundles returns the volumes of the configmaps and claims holding
+// plugin zips, mounted once each, and the plugins to install from them.
+func getPluginBundles(cr *v1alpha1.Grafana) ([]corev1.Volume, []corev1.VolumeMount, []pluginTemplate) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+	mounted := map[string]string{}
+	mount := func(source string, volumeSource corev1.VolumeSource) string {
+		if dir, ok := mounted[source]; ok {
+			return dir
+		}
+		name := fmt.Sprintf("plugin-bundle-%d", len(volumes))
+		dir := pluginBundlesDir + "/" + name
+		volumes = append(volumes, corev1.Volume{Name: name, VolumeSource: volumeSource})
+		mounts = append(mounts, corev1.VolumeMount{Name: name, MountPath: dir, ReadOnly: true})
+		mounted[source] = dir
+		return dir
+	}
+
+	plugins := []pluginTemplate{}
+	for _, plugin := range cr.Spec.Plugins {
+		p := pluginTemplate{Name: plugin.Name, Version: plugin.Version, URL: plugin.URL}
+		switch {
+		case plugin.ConfigMap != nil:
+			defaultMode := int32(0444)
+			dir := mount("configmap/"+plugin.ConfigMap.Name, corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: plugin.ConfigMap.LocalObjectReference,
+					DefaultMode:          &defaultMode,
+				},
+			})
+			p.URL = dir + "/" + plugin.ConfigMap.Key
+		case plugin.PersistentVolumeClaim != nil:
+			dir := mount("claim/"+plugin.PersistentVolumeClaim.ClaimName, corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: plugin.PersistentVolumeClaim.ClaimName,
+					ReadOnly:  true,
+				},
+			})
+			p.URL = dir + "/" + strings.TrimPrefix(plugin.PersistentVolumeClaim.Path, "/")
+		}
+		plugins = append(plugins, p)
+	}
+	return volumes, mounts, plugins
+}
+
+// getPluginVolumeMount shares the installed plugins with grafana. Without
+// spec.plugins grafana keeps the plugins of its data volume.
+func getPluginVolumeMount(cr *v1alpha1.Grafana) []corev1.VolumeMount {
+	if !PluginsEnabled(cr) {
+		return nil
+	}
+	return []corev1.VolumeMount{{Name: GrafanaPlugins, MountPath: PluginsDir}}
+}
+
+// createPluginInstallContainer installs the plugins with the grafana-cli of
+// the grafana image into the plugins volume.
+func createPluginInstallContainer(cr *v1alpha1.Grafana) corev1.Container {
+	_, mounts, plugins := getPluginBundles(cr)
+	var script bytes.Buffer
+	data := struct {
+		Dir     string
+		Plugins []pluginTemplate
+	}{PluginsDir, plugins}
+	if err := tpls.PluginInstall.Execute(&script, data); err != nil {
+		panic(err)
+	}
+
+	return corev1.Container{
+		Name:                     PluginInstallContainerName,
+		Image:                    imageName(os.Getenv(grafanaImageEnv), cr.Spec.BaseImage),
+		Command:                  []string{"/bin/sh", "-c", script.String()},
+		Resources:                *Resources(cr).Init,
+		VolumeMounts:             append(getPluginVolumeMount(cr), mounts...),
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
+		ImagePullPolicy:          "IfNotPresent",
+	}
+}
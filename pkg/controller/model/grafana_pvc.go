@@ -0,0 +1,108 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// PersistentVolumeEnabled is true when the grafana state is on a persistent volume
+func PersistentVolumeEnabled(cr *v1alpha1.Grafana) bool {
+	return cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.Enabled
+}
+
+// userClaimName is the claim provided by the user, GrafanaConfig.PersistentVolumeClaim
+// is the older place for it.
+func userClaimName(cr *v1alpha1.Grafana) string {
+	if cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.ClaimName != "" {
+		return cr.Spec.PersistentVolume.ClaimName
+	}
+	if cr.Spec.GrafanaConfig != nil {
+		return cr.Spec.GrafanaConfig.PersistentVolumeClaim
+	}
+	return ""
+}
+
+// ManagedPersistentVolumeClaim is true when the operator creates the claim of the grafana storage
+func ManagedPersistentVolumeClaim(cr *v1alpha1.Grafana) bool {
+	return PersistentVolumeEnabled(cr) && userClaimName(cr) == ""
+}
+
+// RetainPersistentVolumeClaim is true when the claim created by the operator outlives the CR
+func RetainPersistentVolumeClaim(cr *v1alpha1.Grafana) bool {
+	return cr.Spec.PersistentVolume != nil &&
+		cr.Spec.PersistentVolume.ReclaimPolicy == corev1.PersistentVolumeReclaimRetain
+}
+
+// PersistentVolumeClaimName is the name of the claim mounted as grafana storage
+func PersistentVolumeClaimName(cr *v1alpha1.Grafana) string {
+	if name := userClaimName(cr); name != "" {
+		return name
+	}
+	return instanceName(cr, GrafanaDataVolumes)
+}
+
+// StorageSize is the size requested for the claim created by the operator
+func StorageSize(cr *v1alpha1.Grafana) resource.Quantity {
+	if cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.Size != nil {
+		return *cr.Spec.PersistentVolume.Size
+	}
+	return resource.MustParse(DefaultStorageSize)
+}
+
+func storageClassName(cr *v1alpha1.Grafana) *string {
+	if cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.StorageClass != "" {
+		return &cr.Spec.PersistentVolume.StorageClass
+	}
+	if cr.Spec.GrafanaConfig != nil && cr.Spec.GrafanaConfig.StorageClass != "" {
+		return &cr.Spec.GrafanaConfig.StorageClass
+	}
+	// The cluster default storage class
+	return nil
+}
+
+func accessMode(cr *v1alpha1.Grafana) corev1.PersistentVolumeAccessMode {
+	if cr.Spec.PersistentVolume != nil && cr.Spec.PersistentVolume.AccessMode != "" {
+		return cr.Spec.PersistentVolume.AccessMode
+	}
+	return corev1.ReadWriteOnce
+}
+
+// GrafanaPersistentVolumeClaim is the claim the operator creates for the grafana storage
+func GrafanaPersistentVolumeClaim(cr *v1alpha1.Grafana) *corev1.PersistentVolumeClaim {
+	labels := map[string]string{"app": "grafana", "component": "grafana"}
+	labels = appendCommonLabels(labels)
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PersistentVolumeClaimName(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{accessMode(cr)},
+			StorageClassName: storageClassName(cr),
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: StorageSize(cr),
+				},
+			},
+		},
+	}
+}
@@ -58,6 +58,12 @@ func getVolumeMountsForRouter() []corev1.VolumeMount {
 			MountPath: "/opt/ibm/router/nginx/conf/monitoring-util.lua",
 			SubPath:   "monitoring-util.lua",
 		},
+		// Without a subPath, the kubelet refreshes the orgs after each user sync
+		{
+			Name:      grafanaOrgs,
+			MountPath: "/opt/ibm/router/orgs",
+			ReadOnly:  true,
+		},
 	}
 }
 
@@ -81,18 +87,12 @@ func getRouterProbe(delay, period, timeout, failureThreshold int, iamNamespace s
 		PeriodSeconds:       int32(period),
 		TimeoutSeconds:      int32(timeout),
 		FailureThreshold:    int32(failureThreshold),
+		SuccessThreshold:    1,
 	}
 }
 
 func createRouterContainer(cr *v1alpha1.Grafana) corev1.Container {
 
-	var resources corev1.ResourceRequirements
-	if cr.Spec.RouterConfig != nil && cr.Spec.RouterConfig.Resources != nil {
-		resources = *cr.Spec.RouterConfig.Resources
-	} else {
-		resources = getContainerResource(cr, "Router")
-	}
-
 	image := imageName(os.Getenv(routerImageEnv), cr.Spec.RouterImage)
 
 	return corev1.Container{
@@ -106,11 +106,11 @@ func createRouterContainer(cr *v1alpha1.Grafana) corev1.Container {
 				Protocol:      "TCP",
 			},
 		},
-		Resources:                resources,
+		Resources:                *Resources(cr).Router,
 		LivenessProbe:            getRouterProbe(30, 30, 30, 10, cr.Namespace),
 		ReadinessProbe:           getRouterProbe(32, 20, 30, 10, cr.Namespace),
 		VolumeMounts:             getVolumeMountsForRouter(),
-		Env:                      setupAdminEnv("GF_SECURITY_ADMIN_USER", "GF_SECURITY_ADMIN_PASSWORD"),
+		Env:                      setupAdminEnv(cr, "GF_SECURITY_ADMIN_USER", "GF_SECURITY_ADMIN_PASSWORD"),
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: "File",
 		ImagePullPolicy:          "IfNotPresent",
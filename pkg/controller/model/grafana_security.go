@@ -0,0 +1,238 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// tmpVolume gives each container a writable /tmp under a read only root filesystem
+const tmpVolume = "tmp"
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// restrictedPodSecurityContext is the pod part of the restricted Pod Security Standard
+func restrictedPodSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: boolPtr(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// restrictedSecurityContext is the container part of the restricted Pod
+// Security Standard, with a read only root filesystem.
+func restrictedSecurityContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		Privileged:               boolPtr(false),
+		AllowPrivilegeEscalation: boolPtr(false),
+		RunAsNonRoot:             boolPtr(true),
+		ReadOnlyRootFilesystem:   boolPtr(true),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
+
+// PodSecurityContext returns the restricted pod security context with the overrides of the spec
+func PodSecurityContext(cr *v1alpha1.Grafana) *corev1.PodSecurityContext {
+	sc := restrictedPodSecurityContext()
+	if cr.Spec.SecurityContext == nil || cr.Spec.SecurityContext.Pod == nil {
+		return sc
+	}
+	o := cr.Spec.SecurityContext.Pod.DeepCopy()
+	if o.SELinuxOptions != nil {
+		sc.SELinuxOptions = o.SELinuxOptions
+	}
+	if o.WindowsOptions != nil {
+		sc.WindowsOptions = o.WindowsOptions
+	}
+	if o.RunAsUser != nil {
+		sc.RunAsUser = o.RunAsUser
+	}
+	if o.RunAsGroup != nil {
+		sc.RunAsGroup = o.RunAsGroup
+	}
+	if o.RunAsNonRoot != nil {
+		sc.RunAsNonRoot = o.RunAsNonRoot
+	}
+	if o.SupplementalGroups != nil {
+		sc.SupplementalGroups = o.SupplementalGroups
+	}
+	if o.FSGroup != nil {
+		sc.FSGroup = o.FSGroup
+	}
+	if o.Sysctls != nil {
+		sc.Sysctls = o.Sysctls
+	}
+	if o.FSGroupChangePolicy != nil {
+		sc.FSGroupChangePolicy = o.FSGroupChangePolicy
+	}
+	if o.SeccompProfile != nil {
+		sc.SeccompProfile = o.SeccompProfile
+	}
+	return sc
+}
+
+// ContainerSecurityContext returns the restricted security context of a
+// container with the overrides of the spec
+func ContainerSecurityContext(cr *v1alpha1.Grafana, container string) *corev1.SecurityContext {
+	sc := restrictedSecurityContext()
+	if cr.Spec.SecurityContext == nil {
+		return sc
+	}
+	override, ok := cr.Spec.SecurityContext.Containers[container]
+	if !ok {
+		return sc
+	}
+	o := override.DeepCopy()
+	if o.Capabilities != nil {
+		sc.Capabilities = o.Capabilities
+	}
+	if o.Privileged != nil {
+		sc.Privileged = o.Privileged
+	}
+	if o.SELinuxOptions != nil {
+		sc.SELinuxOptions = o.SELinuxOptions
+	}
+	if o.WindowsOptions != nil {
+		sc.WindowsOptions = o.WindowsOptions
+	}
+	if o.RunAsUser != nil {
+		sc.RunAsUser = o.RunAsUser
+	}
+	if o.RunAsGroup != nil {
+		sc.RunAsGroup = o.RunAsGroup
+	}
+	if o.RunAsNonRoot != nil {
+		sc.RunAsNonRoot = o.RunAsNonRoot
+	}
+	if o.ReadOnlyRootFilesystem != nil {
+		sc.ReadOnlyRootFilesystem = o.ReadOnlyRootFilesystem
+	}
+	if o.AllowPrivilegeEscalation != nil {
+		sc.AllowPrivilegeEscalation = o.AllowPrivilegeEscalation
+	}
+	if o.ProcMount != nil {
+		sc.ProcMount = o.ProcMount
+	}
+	if o.SeccompProfile != nil {
+		sc.SeccompProfile = o.SeccompProfile
+	}
+	return sc
+}
+
+// applySecurityProfile sets the security contexts of the pod and its
+// containers, and mounts a private /tmp into each container.
+func applySecurityProfile(cr *v1alpha1.Grafana, spec *corev1.PodSpec) {
+	spec.SecurityContext = PodSecurityContext(cr)
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: tmpVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			c := &containers[i]
+			c.SecurityContext = ContainerSecurityContext(cr, c.Name)
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+				Name:      tmpVolume,
+				MountPath: "/tmp",
+				SubPath:   c.Name,
+			})
+		}
+	}
+}
+
+// SecurityProfileViolations lists how the security contexts of the pods of cr
+// are weaker than the restricted profile
+func SecurityProfileViolations(cr *v1alpha1.Grafana) []string {
+	spec := getDeploymentSpec(cr).Template.Spec
+	pod := spec.SecurityContext
+	violations := []string{}
+	if pod.RunAsUser != nil && *pod.RunAsUser == 0 {
+		violations = append(violations, "the pod runs as root")
+	}
+	for _, c := range append(spec.InitContainers, spec.Containers...) {
+		sc := c.SecurityContext
+		add := func(format string, args ...interface{}) {
+			violations = append(violations, fmt.Sprintf("container %s ", c.Name)+fmt.Sprintf(format, args...))
+		}
+		runAsNonRoot := pod.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			add("may run as root")
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			add("runs as root")
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			add("is privileged")
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			add("allows privilege escalation")
+		}
+		if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+			add("has a writable root filesystem")
+		}
+		if !dropsAllCapabilities(sc.Capabilities) {
+			add("does not drop all the capabilities")
+		}
+		for _, capability := range capabilitiesAdded(sc.Capabilities) {
+			if capability != "NET_BIND_SERVICE" {
+				add("adds the capability %s", capability)
+			}
+		}
+		seccomp := pod.SeccompProfile
+		if sc.SeccompProfile != nil {
+			seccomp = sc.SeccompProfile
+		}
+		if seccomp == nil || (seccomp.Type != corev1.SeccompProfileTypeRuntimeDefault &&
+			seccomp.Type != corev1.SeccompProfileTypeLocalhost) {
+			add("has no RuntimeDefault or Localhost seccomp profile")
+		}
+	}
+	return violations
+}
+
+func dropsAllCapabilities(capabilities *corev1.Capabilities) bool {
+	if capabilities == nil {
+		return false
+	}
+	for _, capability := range capabilities.Drop {
+		if capability == "ALL" {
+			return true
+		}
+	}
+	return false
+}
+
+func capabilitiesAdded(capabilities *corev1.Capabilities) []corev1.Capability {
+	if capabilities == nil {
+		return nil
+	}
+	return capabilities.Add
+}
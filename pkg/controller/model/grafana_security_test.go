@@ -0,0 +1,132 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestSecurityProfileViolations(t *testing.T) {
+	containers := []string{InitContainerName, "grafana", "router", "dashboard-controller", "ds-proxy"}
+	// each lists the violation of every container but the skipped ones
+	each := func(violation string, skip ...string) []string {
+		violations := []string{}
+	containers:
+		for _, c := range containers {
+			for _, s := range skip {
+				if c == s {
+					continue containers
+				}
+			}
+			violations = append(violations, "container "+c+" "+violation)
+		}
+		return violations
+	}
+	int64Ptr := func(i int64) *int64 { return &i }
+	tests := []struct {
+		name   string
+		config *v1alpha1.SecurityContextConfig
+		want   []string
+	}{
+		{
+			name: "restricted by default",
+			want: []string{},
+		},
+		{
+			name:   "pod allowed to run as root, the containers still run as non root",
+			config: &v1alpha1.SecurityContextConfig{Pod: &corev1.PodSecurityContext{RunAsNonRoot: boolPtr(false)}},
+			want:   []string{},
+		},
+		{
+			name: "container override weaker than the pod",
+			config: &v1alpha1.SecurityContextConfig{
+				Containers: map[string]corev1.SecurityContext{"router": {RunAsNonRoot: boolPtr(false)}},
+			},
+			want: []string{"container router may run as root"},
+		},
+		{
+			name:   "pod running as root",
+			config: &v1alpha1.SecurityContextConfig{Pod: &corev1.PodSecurityContext{RunAsUser: int64Ptr(0)}},
+			want:   []string{"the pod runs as root"},
+		},
+		{
+			name: "unconfined pod seccomp profile",
+			config: &v1alpha1.SecurityContextConfig{
+				Pod: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}},
+			},
+			want: each("has no RuntimeDefault or Localhost seccomp profile"),
+		},
+		{
+			name: "unconfined pod seccomp profile overridden by a container",
+			config: &v1alpha1.SecurityContextConfig{
+				Pod: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}},
+				Containers: map[string]corev1.SecurityContext{"ds-proxy": {
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost},
+				}},
+			},
+			want: each("has no RuntimeDefault or Localhost seccomp profile", "ds-proxy"),
+		},
+		{
+			name: "NET_BIND_SERVICE allowed",
+			config: &v1alpha1.SecurityContextConfig{
+				Containers: map[string]corev1.SecurityContext{"router": {
+					Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}, Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+				}},
+			},
+			want: []string{},
+		},
+		{
+			name: "weakened container",
+			config: &v1alpha1.SecurityContextConfig{
+				Containers: map[string]corev1.SecurityContext{"grafana": {
+					Privileged:               boolPtr(true),
+					AllowPrivilegeEscalation: boolPtr(true),
+					ReadOnlyRootFilesystem:   boolPtr(false),
+					RunAsUser:                int64Ptr(0),
+					Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+				}},
+			},
+			want: []string{
+				"container grafana runs as root",
+				"container grafana is privileged",
+				"container grafana allows privilege escalation",
+				"container grafana has a writable root filesystem",
+				"container grafana does not drop all the capabilities",
+				"container grafana adds the capability NET_ADMIN",
+			},
+		},
+		{
+			name: "override of an unknown container",
+			config: &v1alpha1.SecurityContextConfig{
+				Containers: map[string]corev1.SecurityContext{"sidecar": {Privileged: boolPtr(true)}},
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Grafana{Spec: v1alpha1.GrafanaSpec{SecurityContext: tt.config}}
+			if got := SecurityProfileViolations(cr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got violations %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
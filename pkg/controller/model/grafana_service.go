@@ -16,6 +16,8 @@
 package model
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -66,7 +68,7 @@ func getServicePorts(cr *v1alpha1.Grafana, currentState *corev1.Service) []corev
 			Port:     intPort,
 			TargetPort: intstr.IntOrString{
 				Type:   intstr.Int,
-				IntVal: 8445,
+				IntVal: ServiceTargetPort(cr),
 			},
 		},
 	}
@@ -103,10 +105,7 @@ func getServicePorts(cr *v1alpha1.Grafana, currentState *corev1.Service) []corev
 }
 
 func getGrafanaSelectors(cr *v1alpha1.Grafana) map[string]string {
-	selectors := map[string]string{
-		"app":       "grafana",
-		"component": "grafana",
-	}
+	selectors := InstanceSelector(cr)
 
 	if cr.Spec.Service != nil && cr.Spec.Service.Selector != nil {
 		mergeMaps(selectors, cr.Spec.Service.Selector)
@@ -118,7 +117,7 @@ func getGrafanaSelectors(cr *v1alpha1.Grafana) map[string]string {
 func GrafanaService(cr *v1alpha1.Grafana) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        GrafanaServiceName,
+			Name:        ServiceName(cr),
 			Namespace:   cr.Namespace,
 			Labels:      getServiceLabels(cr),
 			Annotations: getServiceAnnotations(cr),
@@ -142,9 +141,14 @@ func ReconciledGrafanaService(cr *v1alpha1.Grafana, current *corev1.Service) *co
 	return reconciled
 }
 
+// ServiceURL is the in cluster URL of the grafana service of cr
+func ServiceURL(cr *v1alpha1.Grafana) string {
+	return fmt.Sprintf("https://%s.%s.svc:%d", ServiceName(cr), cr.Namespace, DefaultGrafanaPort)
+}
+
 func GrafanaServiceSelector(cr *v1alpha1.Grafana) client.ObjectKey {
 	return client.ObjectKey{
 		Namespace: cr.Namespace,
-		Name:      GrafanaServiceName,
+		Name:      ServiceName(cr),
 	}
 }
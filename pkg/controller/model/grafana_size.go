@@ -0,0 +1,169 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func requirements(memoryRequest, cpuRequest, memoryLimit, cpuLimit string) *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(memoryRequest),
+			corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(memoryLimit),
+			corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+		},
+	}
+}
+
+// sizes are the resources of the containers for each spec.size. Small keeps
+// the resources of the releases without spec.size.
+var sizes = map[string]v1alpha1.ContainerResources{
+	v1alpha1.SizeSmall: {
+		Grafana:   requirements("256Mi", "200m", "512Mi", "500m"),
+		Router:    requirements("256Mi", "200m", "512Mi", "500m"),
+		Dashboard: requirements("256Mi", "200m", "512Mi", "500m"),
+		DSProxy:   requirements("16Mi", "5m", "256Mi", "10m"),
+		Init:      requirements("32Mi", "10m", "128Mi", "100m"),
+	},
+	v1alpha1.SizeMedium: {
+		Grafana:   requirements("512Mi", "500m", "1Gi", "1"),
+		Router:    requirements("256Mi", "200m", "512Mi", "500m"),
+		Dashboard: requirements("256Mi", "200m", "512Mi", "500m"),
+		DSProxy:   requirements("32Mi", "10m", "256Mi", "50m"),
+		Init:      requirements("32Mi", "10m", "128Mi", "100m"),
+	},
+	v1alpha1.SizeLarge: {
+		Grafana:   requirements("1Gi", "1", "2Gi", "2"),
+		Router:    requirements("512Mi", "500m", "1Gi", "1"),
+		Dashboard: requirements("256Mi", "200m", "512Mi", "500m"),
+		DSProxy:   requirements("64Mi", "20m", "512Mi", "100m"),
+		Init:      requirements("32Mi", "10m", "128Mi", "100m"),
+	},
+}
+
+// Size returns the size of the grafana pods, small by default
+func Size(cr *v1alpha1.Grafana) string {
+	if cr.Spec.Size == "" {
+		return v1alpha1.SizeSmall
+	}
+	return cr.Spec.Size
+}
+
+// Resources returns the resources of all the containers. A custom size takes
+// the resources of spec.customResources, and of the small size for the
+// containers left out. Without spec.size, the deprecated resource fields
+// still set the resources of their containers.
+func Resources(cr *v1alpha1.Grafana) v1alpha1.ContainerResources {
+	size := Size(cr)
+	preset, ok := sizes[size]
+	if !ok {
+		preset = sizes[v1alpha1.SizeSmall]
+	}
+	resources := *preset.DeepCopy()
+	custom := cr.Spec.CustomResources
+	if size != v1alpha1.SizeCustom {
+		custom = nil
+	}
+	if cr.Spec.Size == "" {
+		if deprecated, fields := deprecatedResources(cr); len(fields) > 0 {
+			custom = deprecated
+		}
+	}
+	if custom == nil {
+		return resources
+	}
+	custom = custom.DeepCopy()
+	for _, c := range []struct {
+		from *corev1.ResourceRequirements
+		to   **corev1.ResourceRequirements
+	}{
+		{custom.Grafana, &resources.Grafana},
+		{custom.Router, &resources.Router},
+		{custom.Dashboard, &resources.Dashboard},
+		{custom.DSProxy, &resources.DSProxy},
+		{custom.Init, &resources.Init},
+	} {
+		if c.from != nil {
+			*c.to = c.from
+		}
+	}
+	return resources
+}
+
+// multipliedResources are the resources the deprecated spec.resources gave
+// to a container, times the default ones
+func multipliedResources(times int) *corev1.ResourceRequirements {
+	return requirements(strconv.Itoa(256*times)+"Mi", strconv.Itoa(200*times)+"m",
+		strconv.Itoa(512*times)+"Mi", strconv.Itoa(500*times)+"m")
+}
+
+// deprecatedResources returns the resources the deprecated fields of the
+// spec give to the containers, and the fields set. The per-container fields
+// win over the spec.resources multipliers, as they did. The spec is only
+// read: it may be managed by another operator, which would put them back.
+func deprecatedResources(cr *v1alpha1.Grafana) (*v1alpha1.ContainerResources, []string) {
+	spec := &cr.Spec
+	custom := &v1alpha1.ContainerResources{}
+	fields := []string{}
+	if spec.Resources != nil {
+		fields = append(fields, "spec.resources")
+		for _, m := range []struct {
+			times int
+			to    **corev1.ResourceRequirements
+		}{
+			{spec.Resources.Grafana, &custom.Grafana},
+			{spec.Resources.Router, &custom.Router},
+			{spec.Resources.Dashboard, &custom.Dashboard},
+		} {
+			// A multiplier left out used to give no resources at all, it gets the small size
+			if m.times > 0 {
+				*m.to = multipliedResources(m.times)
+			}
+		}
+	}
+	if spec.GrafanaConfig != nil && spec.GrafanaConfig.Resources != nil {
+		fields = append(fields, "spec.grafanaConfig.resources")
+		custom.Grafana = spec.GrafanaConfig.Resources
+	}
+	if spec.RouterConfig != nil && spec.RouterConfig.Resources != nil {
+		fields = append(fields, "spec.routerConfig.resources")
+		custom.Router = spec.RouterConfig.Resources
+	}
+	if spec.DashboardsConfig != nil && spec.DashboardsConfig.Resources != nil {
+		fields = append(fields, "spec.dashboardConfig.resources")
+		custom.Dashboard = spec.DashboardsConfig.Resources
+	}
+	if spec.DataSourceConfig != nil && spec.DataSourceConfig.ProxyResources != nil {
+		fields = append(fields, "spec.datasourceConfig.proxyResources")
+		custom.DSProxy = spec.DataSourceConfig.ProxyResources
+	}
+	return custom, fields
+}
+
+// DeprecatedResourceFields returns the deprecated resource fields set in the spec
+func DeprecatedResourceFields(cr *v1alpha1.Grafana) []string {
+	_, fields := deprecatedResources(cr)
+	return fields
+}
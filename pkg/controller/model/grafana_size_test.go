@@ -0,0 +1,133 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+func TestResources(t *testing.T) {
+	small := sizes[v1alpha1.SizeSmall]
+	custom := requirements("2Gi", "1", "4Gi", "2")
+	// with returns the resources of a size with some containers changed
+	with := func(size string, change func(*v1alpha1.ContainerResources)) v1alpha1.ContainerResources {
+		preset := sizes[size]
+		resources := *preset.DeepCopy()
+		if change != nil {
+			change(&resources)
+		}
+		return resources
+	}
+	tests := []struct {
+		name       string
+		spec       v1alpha1.GrafanaSpec
+		want       v1alpha1.ContainerResources
+		deprecated []string
+	}{
+		{
+			name: "small by default",
+			want: small,
+		},
+		{
+			name: "preset size",
+			spec: v1alpha1.GrafanaSpec{Size: v1alpha1.SizeLarge},
+			want: sizes[v1alpha1.SizeLarge],
+		},
+		{
+			name: "custom size falls back to small for the containers left out",
+			spec: v1alpha1.GrafanaSpec{
+				Size:            v1alpha1.SizeCustom,
+				CustomResources: &v1alpha1.ContainerResources{Grafana: custom},
+			},
+			want: with(v1alpha1.SizeSmall, func(r *v1alpha1.ContainerResources) { r.Grafana = custom }),
+		},
+		{
+			name: "custom resources ignored by a preset size",
+			spec: v1alpha1.GrafanaSpec{
+				Size:            v1alpha1.SizeMedium,
+				CustomResources: &v1alpha1.ContainerResources{Grafana: custom},
+			},
+			want: sizes[v1alpha1.SizeMedium],
+		},
+		{
+			name: "deprecated multipliers, a multiplier of 0 keeps the small size",
+			spec: v1alpha1.GrafanaSpec{Resources: &v1alpha1.GrafanaResources{Grafana: 2, Router: 0, Dashboard: 1}},
+			want: with(v1alpha1.SizeSmall, func(r *v1alpha1.ContainerResources) {
+				r.Grafana = requirements("512Mi", "400m", "1Gi", "1")
+			}),
+			deprecated: []string{"spec.resources"},
+		},
+		{
+			name: "deprecated container resources win over the multipliers",
+			spec: v1alpha1.GrafanaSpec{
+				Resources:        &v1alpha1.GrafanaResources{Grafana: 2, Router: 3},
+				GrafanaConfig:    &v1alpha1.GrafanaConfig{Resources: custom},
+				DataSourceConfig: &v1alpha1.DataSourceConfig{ProxyResources: custom},
+			},
+			want: with(v1alpha1.SizeSmall, func(r *v1alpha1.ContainerResources) {
+				r.Grafana = custom
+				r.Router = requirements("768Mi", "600m", "1536Mi", "1500m")
+				r.DSProxy = custom
+			}),
+			deprecated: []string{"spec.resources", "spec.grafanaConfig.resources", "spec.datasourceConfig.proxyResources"},
+		},
+		{
+			name: "deprecated fields ignored once the size is set",
+			spec: v1alpha1.GrafanaSpec{
+				Size:             v1alpha1.SizeMedium,
+				Resources:        &v1alpha1.GrafanaResources{Grafana: 2},
+				DashboardsConfig: &v1alpha1.DashboardConfig{Resources: custom},
+			},
+			want:       sizes[v1alpha1.SizeMedium],
+			deprecated: []string{"spec.resources", "spec.dashboardConfig.resources"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1alpha1.Grafana{Spec: tt.spec}
+			spec := cr.Spec.DeepCopy()
+			got := Resources(cr)
+			for _, c := range []struct {
+				name      string
+				got, want *corev1.ResourceRequirements
+			}{
+				{"grafana", got.Grafana, tt.want.Grafana},
+				{"router", got.Router, tt.want.Router},
+				{"dashboard", got.Dashboard, tt.want.Dashboard},
+				{"dsproxy", got.DSProxy, tt.want.DSProxy},
+				{"init", got.Init, tt.want.Init},
+			} {
+				if !equality.Semantic.DeepEqual(c.got, c.want) {
+					t.Errorf("got %s resources %v, want %v", c.name, c.got, c.want)
+				}
+			}
+			if fields := DeprecatedResourceFields(cr); len(fields) > 0 || len(tt.deprecated) > 0 {
+				if !reflect.DeepEqual(fields, tt.deprecated) {
+					t.Errorf("got deprecated fields %v, want %v", fields, tt.deprecated)
+				}
+			}
+			if !reflect.DeepEqual(&cr.Spec, spec) {
+				t.Errorf("the spec was changed to %+v", cr.Spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	"net"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// Keys of the secret referenced by spec.smtp.secretRef
+const (
+	SMTPUserKey     = "username"
+	SMTPPasswordKey = "password"
+)
+
+// Keys of the secret referenced by spec.smtp.certSecretRef
+const (
+	SMTPCertKey = "tls.crt"
+	SMTPKeyKey  = "tls.key"
+)
+
+const (
+	smtpCertVolume = "smtp-certs"
+	smtpCertDir    = "/opt/ibm/monitoring/smtp-certs"
+)
+
+// smtpTemplate is the mail server configuration rendered into grafana.ini
+type smtpTemplate struct {
+	Address        string
+	FromAddress    string
+	FromName       string
+	SkipVerify     bool
+	EHLOIdentity   string
+	StartTLSPolicy string
+	CertFile       string
+	KeyFile        string
+}
+
+// SMTPEnabled is true when grafana sends emails
+func SMTPEnabled(cr *v1alpha1.Grafana) bool {
+	return cr.Spec.SMTP != nil
+}
+
+// SMTPSecretNames returns the secrets the SMTP configuration of cr reads
+func SMTPSecretNames(cr *v1alpha1.Grafana) []string {
+	if !SMTPEnabled(cr) {
+		return nil
+	}
+	names := []string{}
+	for _, ref := range []*corev1.LocalObjectReference{cr.Spec.SMTP.SecretRef, cr.Spec.SMTP.CertSecretRef} {
+		if ref != nil && ref.Name != "" {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// SMTPAddress returns the host:port of the SMTP server, port 25 when the host has none
+func SMTPAddress(cr *v1alpha1.Grafana) string {
+	host := cr.Spec.SMTP.Host
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, "25")
+}
+
+func getSMTPTemplate(cr *v1alpha1.Grafana) *smtpTemplate {
+	if !SMTPEnabled(cr) {
+		return nil
+	}
+	smtp := &smtpTemplate{
+		Address:        SMTPAddress(cr),
+		FromAddress:    cr.Spec.SMTP.FromAddress,
+		FromName:       cr.Spec.SMTP.FromName,
+		SkipVerify:     cr.Spec.SMTP.SkipVerify,
+		EHLOIdentity:   cr.Spec.SMTP.EHLOIdentity,
+		StartTLSPolicy: cr.Spec.SMTP.StartTLSPolicy,
+	}
+	if cr.Spec.SMTP.CertSecretRef != nil {
+		smtp.CertFile = path.Join(smtpCertDir, SMTPCertKey)
+		smtp.KeyFile = path.Join(smtpCertDir, SMTPKeyKey)
+	}
+	return smtp
+}
+
+// getSMTPCertVolume returns the volume of the SMTP client certificate, if any
+func getSMTPCertVolume(cr *v1alpha1.Grafana) []corev1.Volume {
+	if !SMTPEnabled(cr) || cr.Spec.SMTP.CertSecretRef == nil {
+		return nil
+	}
+	return []corev1.Volume{createVolumeFromSecret(cr.Spec.SMTP.CertSecretRef.Name, smtpCertVolume)}
+}
+
+func getSMTPCertVolumeMount(cr *v1alpha1.Grafana) []corev1.VolumeMount {
+	if !SMTPEnabled(cr) || cr.Spec.SMTP.CertSecretRef == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{{Name: smtpCertVolume, MountPath: smtpCertDir, ReadOnly: true}}
+}
+
+// getSMTPEnv passes the SMTP credentials to grafana
+// without writing them into the grafana.ini configmap.
+func getSMTPEnv(cr *v1alpha1.Grafana) []corev1.EnvVar {
+	if !SMTPEnabled(cr) || cr.Spec.SMTP.SecretRef == nil {
+		return nil
+	}
+	secretKeyRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: *cr.Spec.SMTP.SecretRef,
+				Key:                  key,
+			},
+		}
+	}
+	return []corev1.EnvVar{
+		{Name: "GF_SMTP_USER", ValueFrom: secretKeyRef(SMTPUserKey)},
+		{Name: "GF_SMTP_PASSWORD", ValueFrom: secretKeyRef(SMTPPasswordKey)},
+	}
+}
@@ -0,0 +1,148 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package model
+
+import (
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ingressv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cert "github.com/ibm/ibm-cert-manager-operator/apis/certmanager/v1alpha1"
+
+	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
+)
+
+// InstanceLabel tells apart the pods of the Grafana instances of one namespace.
+const InstanceLabel = "grafana.operator.ibm.com/instance"
+
+// All the objects of a Grafana CR are named after the CR, so that several
+// instances can live in the same namespace. The fixed names such as
+// GrafanaDeploymentName are the names used when only one instance was
+// supported, they are only kept to migrate the objects of those releases.
+func instanceName(cr *v1alpha1.Grafana, suffix string) string {
+	return cr.Name + "-" + suffix
+}
+
+// DeploymentName is the name of the grafana deployment of cr
+func DeploymentName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, "grafana")
+}
+
+// ServiceName is the name of the grafana service of cr
+func ServiceName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, "grafana")
+}
+
+// IngressName is the name of the grafana ingress of cr
+func IngressName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, GrafanaIngressName)
+}
+
+// AdminSecretName is the name of the secret holding the grafana admin credentials of cr
+func AdminSecretName(cr *v1alpha1.Grafana) string {
+	if cr.Spec.AdminSecretRef != nil && cr.Spec.AdminSecretRef.Name != "" {
+		return cr.Spec.AdminSecretRef.Name
+	}
+	return GeneratedAdminSecretName(cr)
+}
+
+// ReferencedSecretNames returns the secrets of the user cr reads: the admin
+// secret of spec.adminSecretRef, the database and the SMTP secrets
+func ReferencedSecretNames(cr *v1alpha1.Grafana) []string {
+	names := []string{}
+	if cr.Spec.AdminSecretRef != nil && cr.Spec.AdminSecretRef.Name != "" {
+		names = append(names, cr.Spec.AdminSecretRef.Name)
+	}
+	names = append(names, DatabaseSecretNames(cr)...)
+	return append(names, SMTPSecretNames(cr)...)
+}
+
+// GeneratedAdminSecretName is the name of the admin secret the operator generates
+// when spec.adminSecretRef is not set
+func GeneratedAdminSecretName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, GrafanaAdminSecretName)
+}
+
+// DSProxyConfigSecretName is the name of the datasource proxy configuration secret of cr
+func DSProxyConfigSecretName(cr *v1alpha1.Grafana) string {
+	return instanceName(cr, DSProxyConfigSecName)
+}
+
+// configMapName is the name of one of the configmaps rendered for cr
+func configMapName(cr *v1alpha1.Grafana, name string) string {
+	return instanceName(cr, name)
+}
+
+// InstanceSelector matches the pods of cr only
+func InstanceSelector(cr *v1alpha1.Grafana) map[string]string {
+	return map[string]string{
+		"app":         "grafana",
+		"component":   "grafana",
+		InstanceLabel: cr.Name,
+	}
+}
+
+// LegacyObjects returns the objects a single instance release created for cr,
+// whose names differ from the current ones. They are deleted once the
+// objects with the current names are in place.
+func LegacyObjects(cr *v1alpha1.Grafana) []client.Object {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: cr.Namespace}
+	}
+	legacy := []client.Object{
+		&appv1.Deployment{ObjectMeta: meta(GrafanaDeploymentName)},
+		&corev1.Service{ObjectMeta: meta(GrafanaServiceName)},
+		&ingressv1.Ingress{ObjectMeta: meta(GrafanaIngressName)},
+		&corev1.Secret{ObjectMeta: meta(GrafanaAdminSecretName)},
+		&corev1.Secret{ObjectMeta: meta(DSProxyConfigSecName)},
+		&corev1.ConfigMap{ObjectMeta: meta(grafanaDefaultDashboard)},
+	}
+	for name := range FileKeys {
+		legacy = append(legacy, &corev1.ConfigMap{ObjectMeta: meta(name)})
+	}
+	if cr.Spec.TLSSecretName == "" {
+		legacy = append(legacy, &cert.Certificate{ObjectMeta: meta(DefaultCertSecretName)})
+	}
+
+	objects := []client.Object{}
+	for _, obj := range legacy {
+		if !hasCurrentName(cr, obj) {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// hasCurrentName is true when a legacy name is also the current name,
+// e.g. the deployment of the default CR named ibm-monitoring.
+func hasCurrentName(cr *v1alpha1.Grafana, obj client.Object) bool {
+	switch obj.(type) {
+	case *appv1.Deployment:
+		return obj.GetName() == DeploymentName(cr)
+	case *corev1.Service:
+		return obj.GetName() == ServiceName(cr)
+	case *ingressv1.Ingress:
+		return obj.GetName() == IngressName(cr)
+	case *cert.Certificate:
+		return obj.GetName() == CertSecretName(cr)
+	case *corev1.Secret:
+		return obj.GetName() == AdminSecretName(cr) || obj.GetName() == DSProxyConfigSecretName(cr)
+	default:
+		return false
+	}
+}
@@ -17,20 +17,14 @@ package model
 
 import (
 	"os"
-	"reflect"
 	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/IBM/ibm-monitoring-grafana-operator/pkg/apis/operator/v1alpha1"
 )
 
-var memoryRequest int = 256
-var cpuRequest int = 200
-var memoryLimit int = 512
-var cpuLimit int = 500
 var dashNamespaces string
 
 func getDashNamespaces(cr *v1alpha1.Grafana) string {
@@ -50,6 +44,15 @@ func prometheusInfo(cr *v1alpha1.Grafana) (host string, port int32) {
 
 	return host, port
 }
+
+// ClusterPort is the HTTPS port grafana listens on inside the pod
+func ClusterPort(cr *v1alpha1.Grafana) int32 {
+	if cr.Spec.ClusterPort != 0 {
+		return cr.Spec.ClusterPort
+	}
+	return DefaultClusterPort
+}
+
 func IssuerName(cr *v1alpha1.Grafana) string {
 	issuer := "cs-ca-issuer"
 	if cr.Spec.Issuer != "" {
@@ -91,45 +94,9 @@ func imageName(defaultV string, overwrite string) string {
 
 }
 
-func getContainerResource(cr *v1alpha1.Grafana, name string) corev1.ResourceRequirements {
-
-	var resources *v1alpha1.GrafanaResources
-	var times int
-	if cr.Spec.Resources != nil {
-		resources = cr.Spec.Resources
-	} else {
-		times = 1
-	}
-
-	if resources != nil {
-		r := reflect.ValueOf(resources)
-		value := reflect.Indirect(r).FieldByName(name)
-		times = int(value.Int())
-	}
-
-	return getResource(times)
-}
-
-func getResource(times int) corev1.ResourceRequirements {
-
-	MR := strconv.Itoa(memoryRequest*times) + "Mi"
-	CR := strconv.Itoa(cpuRequest*times) + "m"
-	ML := strconv.Itoa(memoryLimit*times) + "Mi"
-	CL := strconv.Itoa(cpuLimit*times) + "m"
-	return corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse(MR),
-			corev1.ResourceCPU:    resource.MustParse(CR),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse(ML),
-			corev1.ResourceCPU:    resource.MustParse(CL),
-		},
-	}
-
-}
-
-func createVolumeFromCM(name string) corev1.Volume {
+// createVolumeFromCM mounts the configmap rendered from the template name.
+// The volume keeps the template name, the configmap is named after the CR.
+func createVolumeFromCM(cr *v1alpha1.Grafana, name string) corev1.Volume {
 
 	var stringMode string
 
@@ -146,7 +113,7 @@ func createVolumeFromCM(name string) corev1.Volume {
 		VolumeSource: corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: name,
+					Name: configMapName(cr, name),
 				},
 				DefaultMode: &defaultMode,
 			},
@@ -165,14 +132,14 @@ func createVolumeFromSecret(secretName, volumeName string) corev1.Volume {
 	}
 }
 
-func setupAdminEnv(username, password string) []corev1.EnvVar {
+func setupAdminEnv(cr *v1alpha1.Grafana, username, password string) []corev1.EnvVar {
 	return []corev1.EnvVar{
 		{
 			Name: username,
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: GrafanaAdminSecretName,
+						Name: AdminSecretName(cr),
 					},
 					Key: GrafanaAdminUserEnvVar,
 				},
@@ -183,7 +150,7 @@ func setupAdminEnv(username, password string) []corev1.EnvVar {
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: GrafanaAdminSecretName,
+						Name: AdminSecretName(cr),
 					},
 					Key: GrafanaAdminPasswordEnvVar,
 				},
@@ -17,6 +17,10 @@ package model
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
@@ -41,7 +45,7 @@ var (
 	grafanaCRD              string = "grafana-crd-entry"
 	dsConfig                string = "grafana-ds-entry-config"
 	grafanaConfig           string = "grafana-config"
-	grafanaCredentialStr    string = "YWRtaW46YWRtaW4="
+	grafanaOrgs             string = "grafana-orgs"
 )
 
 type fileKeys map[string]map[string]*template.Template
@@ -53,10 +57,15 @@ type templateData struct {
 	ClusterDomain      string
 	GrafanaFullName    string
 	PrometheusFullName string
-	GrafanaCredential  string
 	ClusterPort        int32
 	PrometheusPort     int32
 	GrafanaPort        int32
+	IngressPath        string
+	ServeFromSubPath   bool
+	Database           *v1alpha1.GrafanaDatabase
+	Auth               authTemplate
+	SMTP               *smtpTemplate
+	UnsignedPlugins    string
 }
 
 // FileKeys stores the configmap name and file key
@@ -89,7 +98,7 @@ func createConfigmap(namespace, name string, data map[string]string) *corev1.Con
 	return &configmap
 }
 
-func createDefaultDashboard(namespace string) *corev1.ConfigMap {
+func createDefaultDashboard(cr *v1alpha1.Grafana) *corev1.ConfigMap {
 	configData := map[string]string{}
 
 	for file, data := range dashboards.DefaultDashboards {
@@ -100,8 +109,8 @@ func createDefaultDashboard(namespace string) *corev1.ConfigMap {
 	labels = appendCommonLabels(labels)
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      grafanaDefaultDashboard,
-			Namespace: namespace,
+			Name:      configMapName(cr, grafanaDefaultDashboard),
+			Namespace: cr.Namespace,
 			Labels:    labels,
 		},
 		Data: configData,
@@ -114,17 +123,13 @@ func createDefaultDashboard(namespace string) *corev1.ConfigMap {
 func ReconcileConfigMaps(cr *v1alpha1.Grafana) []*corev1.ConfigMap {
 	configmaps := []*corev1.ConfigMap{}
 	namespace := cr.Namespace
-	var prometheusPort, httpPort int32
+	var prometheusPort int32
 	var prometheusFullName string
 
-	if cr.Spec.ClusterPort != 0 {
-		httpPort = cr.Spec.ClusterPort
-	} else {
-		httpPort = DefaultClusterPort
-	}
+	httpPort := ClusterPort(cr)
 	prometheusFullName, prometheusPort = prometheusInfo(cr)
 	grafanaPort := DefaultGrafanaPort
-	grafanaFullName := GrafanaServiceName
+	grafanaFullName := ServiceName(cr)
 
 	tplData := templateData{
 		Namespace:          namespace,
@@ -135,7 +140,14 @@ func ReconcileConfigMaps(cr *v1alpha1.Grafana) []*corev1.ConfigMap {
 		PrometheusPort:     prometheusPort,
 		GrafanaFullName:    grafanaFullName,
 		GrafanaPort:        grafanaPort,
-		GrafanaCredential:  grafanaCredentialStr,
+		IngressPath:        IngressPath(cr),
+		ServeFromSubPath:   ServeFromSubPath(cr),
+		Auth:               getAuthTemplate(cr),
+		SMTP:               getSMTPTemplate(cr),
+		UnsignedPlugins:    UnsignedPlugins(cr),
+	}
+	if ExternalDatabase(cr) {
+		tplData.Database = cr.Spec.Database
 	}
 
 	for file, dValue := range FileKeys {
@@ -148,36 +160,81 @@ func ReconcileConfigMaps(cr *v1alpha1.Grafana) []*corev1.ConfigMap {
 			}
 			data[name] = buff.String()
 		}
-		configmaps = append(configmaps, createConfigmap(cr.Namespace, file, data))
+		if file == grafanaConfig {
+			data["grafana.ini"] = mergeINI(data["grafana.ini"], INIOverrides(cr))
+		}
+		configmaps = append(configmaps, createConfigmap(cr.Namespace, configMapName(cr, file), data))
 	}
 
-	configmaps = append(configmaps, createDefaultDashboard(cr.Namespace))
+	configmaps = append(configmaps, createDefaultDashboard(cr))
 	return configmaps
 }
 
-// CreateGrafanaSecret create a secret from the user/passwd from config file
-func CreateGrafanaSecret(cr *v1alpha1.Grafana) *corev1.Secret {
+// GrafanaOrgsConfigMap lists the grafana org id of each namespace for the
+// router, which selects the org of a request with it. It is not part of the
+// config hash: the kubelet refreshes it in the running pods.
+func GrafanaOrgsConfigMap(cr *v1alpha1.Grafana, orgs map[string]int64) (*corev1.ConfigMap, error) {
+	data, err := json.Marshal(orgs)
+	if err != nil {
+		return nil, err
+	}
+	return createConfigmap(cr.Namespace, configMapName(cr, grafanaOrgs), map[string]string{
+		GrafanaOrgsKey: string(data),
+	}), nil
+}
+
+// CreateGrafanaSecret create the admin secret with a random password
+func CreateGrafanaSecret(cr *v1alpha1.Grafana) (*corev1.Secret, error) {
 
-	var password, user string = "admin", "admin"
-	data := map[string][]byte{"username": []byte(user), "password": []byte(password)}
+	password, err := GeneratePassword()
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		GrafanaAdminUserEnvVar:     []byte(DefaultAdminUser),
+		GrafanaAdminPasswordEnvVar: []byte(password),
+	}
 
 	labels := map[string]string{"app": "grafana", "component": "grafana"}
 	labels = appendCommonLabels(labels)
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      GrafanaAdminSecretName,
+			Name:      GeneratedAdminSecretName(cr),
 			Namespace: cr.Namespace,
 			Labels:    labels,
 		},
 		Type: "Opaque",
 		Data: data,
+	}, nil
+}
+
+// GeneratePassword returns a random password for the grafana admin
+func GeneratePassword() (string, error) {
+	buf := make([]byte, passwordBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GrafanaCredential returns the base64 user:password of an admin secret,
+// as sent in the basic authorization header.
+func GrafanaCredential(secret *corev1.Secret) (string, error) {
+	user, ok := secret.Data[GrafanaAdminUserEnvVar]
+	if !ok || len(user) == 0 {
+		return "", fmt.Errorf("admin secret %s has no %s key", secret.Name, GrafanaAdminUserEnvVar)
+	}
+	password, ok := secret.Data[GrafanaAdminPasswordEnvVar]
+	if !ok || len(password) == 0 {
+		return "", fmt.Errorf("admin secret %s has no %s key", secret.Name, GrafanaAdminPasswordEnvVar)
 	}
+	return base64.StdEncoding.EncodeToString([]byte(string(user) + ":" + string(password))), nil
 }
 
 // GrafanaSecretSelector to retrieve the secret
 func GrafanaSecretSelector(cr *v1alpha1.Grafana) client.ObjectKey {
 	return client.ObjectKey{
-		Name:      GrafanaAdminSecretName,
+		Name:      AdminSecretName(cr),
 		Namespace: cr.Namespace,
 	}
 }
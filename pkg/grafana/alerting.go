@@ -0,0 +1,97 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Alert is a dashboard panel alert rule of the org of the client
+type Alert struct {
+	ID             int64  `json:"id"`
+	DashboardID    int64  `json:"dashboardId"`
+	DashboardUID   string `json:"dashboardUid"`
+	PanelID        int64  `json:"panelId"`
+	Name           string `json:"name"`
+	State          string `json:"state"`
+	ExecutionError string `json:"executionError,omitempty"`
+}
+
+// NotificationChannel is where grafana sends the alert notifications
+type NotificationChannel struct {
+	ID        int64                  `json:"id,omitempty"`
+	UID       string                 `json:"uid,omitempty"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	IsDefault bool                   `json:"isDefault"`
+	Settings  map[string]interface{} `json:"settings,omitempty"`
+}
+
+// ListAlerts returns the alerts of the org, only those in the given
+// states, such as alerting or no_data, when states are set.
+func (c *Client) ListAlerts(ctx context.Context, states ...string) ([]Alert, error) {
+	query := url.Values{}
+	for _, state := range states {
+		query.Add("state", state)
+	}
+	alerts := []Alert{}
+	if err := c.do(ctx, http.MethodGet, "/api/alerts", query, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// PauseAlert pauses or resumes an alert
+func (c *Client) PauseAlert(ctx context.Context, id int64, paused bool) error {
+	body := map[string]bool{"paused": paused}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/alerts/%d/pause", id), nil, body, nil)
+}
+
+// ListNotificationChannels returns the notification channels of the org
+func (c *Client) ListNotificationChannels(ctx context.Context) ([]NotificationChannel, error) {
+	channels := []NotificationChannel{}
+	if err := c.do(ctx, http.MethodGet, "/api/alert-notifications", nil, nil, &channels); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// CreateNotificationChannel creates a notification channel
+func (c *Client) CreateNotificationChannel(ctx context.Context, channel NotificationChannel) (*NotificationChannel, error) {
+	created := &NotificationChannel{}
+	if err := c.do(ctx, http.MethodPost, "/api/alert-notifications", nil, channel, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateNotificationChannel updates the notification channel with the id of channel
+func (c *Client) UpdateNotificationChannel(ctx context.Context, channel NotificationChannel) (*NotificationChannel, error) {
+	updated := &NotificationChannel{}
+	path := fmt.Sprintf("/api/alert-notifications/%d", channel.ID)
+	if err := c.do(ctx, http.MethodPut, path, nil, channel, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteNotificationChannel deletes the notification channel with the given id
+func (c *Client) DeleteNotificationChannel(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/alert-notifications/%d", id), nil, nil, nil)
+}
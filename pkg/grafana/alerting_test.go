@@ -0,0 +1,93 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAlerts(t *testing.T) {
+	c := newTestClient(t,
+		route{
+			method: http.MethodGet,
+			path:   "/api/alerts",
+			answer: []Alert{{ID: 1, Name: "High CPU", State: "alerting"}},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.URL.Query()["state"]; !reflect.DeepEqual(got, []string{"alerting", "no_data"}) {
+					t.Errorf("got states %v, want alerting and no_data", got)
+				}
+			},
+		},
+		route{
+			method: http.MethodPost,
+			path:   "/api/alerts/1/pause",
+			check: func(t *testing.T, r *http.Request) {
+				body := map[string]bool{}
+				decodeBody(t, r, &body)
+				if !body["paused"] {
+					t.Errorf("got body %v, want the alert paused", body)
+				}
+			},
+		},
+	)
+	ctx := context.Background()
+	alerts, err := c.ListAlerts(ctx, "alerting", "no_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].State != "alerting" {
+		t.Errorf("got alerts %+v", alerts)
+	}
+	if err := c.PauseAlert(ctx, 1, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNotificationChannels(t *testing.T) {
+	channel := NotificationChannel{Name: "ops", Type: "email", Settings: map[string]interface{}{"addresses": "ops@example.com"}}
+	c := newTestClient(t,
+		route{method: http.MethodPost, path: "/api/alert-notifications", answer: NotificationChannel{ID: 2, Name: "ops", Type: "email"}},
+		route{method: http.MethodPut, path: "/api/alert-notifications/2", answer: NotificationChannel{ID: 2, Name: "ops", Type: "email", IsDefault: true}},
+		route{method: http.MethodGet, path: "/api/alert-notifications", answer: []NotificationChannel{{ID: 2, Name: "ops"}}},
+		route{method: http.MethodDelete, path: "/api/alert-notifications/2"},
+	)
+	ctx := context.Background()
+	created, err := c.CreateNotificationChannel(ctx, channel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created.IsDefault = true
+	updated, err := c.UpdateNotificationChannel(ctx, *created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.IsDefault {
+		t.Errorf("got channel %+v, want the default one", updated)
+	}
+	channels, err := c.ListNotificationChannels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 {
+		t.Errorf("got channels %+v", channels)
+	}
+	if err := c.DeleteNotificationChannel(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,195 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds every request sent to grafana
+const DefaultTimeout = time.Second * 10
+
+// Client calls the grafana HTTP API as the grafana admin
+type Client struct {
+	baseURL    string
+	user       string
+	password   string
+	orgID      int64
+	httpClient *http.Client
+}
+
+// StatusError is returned when grafana answers with an unexpected status code
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("grafana %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsStatus is true when err is a StatusError with the given status code
+func IsStatus(err error, code int) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == code
+}
+
+// New returns a client of the grafana listening on baseURL,
+// e.g. https://10.0.0.1:8443, authenticated with basic auth when user is set.
+// A nil tlsConfig uses the system roots.
+func New(baseURL, user, password string, tlsConfig *tls.Config) *Client {
+	return &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		user:     user,
+		password: password,
+		httpClient: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+}
+
+// WithOrg returns a client acting in the org orgID instead of the current
+// org of the user, for the org scoped folders, dashboards, datasources and alerts.
+func (c *Client) WithOrg(orgID int64) *Client {
+	org := *c
+	org.orgID = orgID
+	return &org
+}
+
+// TLSConfig verifies grafana with the caPEM bundle under serverName. When
+// certPEM and keyPEM are set, they are presented as client certificate.
+func TLSConfig(caPEM, certPEM, keyPEM []byte, serverName string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(caPEM) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificate found in the grafana CA bundle")
+		}
+		config.RootCAs = pool
+	}
+	if len(certPEM) != 0 && len(keyPEM) != 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// LoadTLSConfig reads the ca.crt, tls.crt and tls.key files of a mounted
+// certificate secret, such as the grafana certificates, and returns
+// the TLSConfig presenting them.
+func LoadTLSConfig(dir, serverName string) (*tls.Config, error) {
+	read := func(name string) ([]byte, error) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return data, err
+	}
+	caPEM, err := read("ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	certPEM, err := read("tls.crt")
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := read("tls.key")
+	if err != nil {
+		return nil, err
+	}
+	return TLSConfig(caPEM, certPEM, keyPEM, serverName)
+}
+
+// do sends a request with an optional JSON body and decodes the JSON answer into out.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	u := c.baseURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	if c.orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(c.orgID, 10))
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: errorMessage(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// errorMessage extracts the message of a grafana error answer
+func errorMessage(data []byte) string {
+	answer := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(data, &answer); err == nil && answer.Message != "" {
+		return answer.Message
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,296 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// route is the request a test expects and the answer of the fake grafana
+type route struct {
+	method string
+	path   string
+	status int
+	answer interface{}
+	// check inspects the request
+	check func(t *testing.T, r *http.Request)
+}
+
+// newTestClient starts a fake grafana serving routes in order
+func newTestClient(t *testing.T, routes ...route) *Client {
+	t.Helper()
+	next := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if next >= len(routes) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rt := routes[next]
+		next++
+		if r.Method != rt.method || r.URL.Path != rt.path {
+			t.Errorf("got request %s %s, want %s %s", r.Method, r.URL.Path, rt.method, rt.path)
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			t.Errorf("request %s %s is not authenticated as the admin", r.Method, r.URL.Path)
+		}
+		if rt.check != nil {
+			rt.check(t, r)
+		}
+		status := rt.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if rt.answer != nil {
+			_ = json.NewEncoder(w).Encode(rt.answer)
+		}
+	}))
+	t.Cleanup(func() {
+		server.Close()
+		if next != len(routes) {
+			t.Errorf("got %d requests, want %d", next, len(routes))
+		}
+	})
+	return New(server.URL, "admin", "secret", nil)
+}
+
+// decodeBody decodes the JSON body of a request
+func decodeBody(t *testing.T, r *http.Request, into interface{}) {
+	t.Helper()
+	if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q, want application/json", ct)
+	}
+	if err := json.NewDecoder(r.Body).Decode(into); err != nil {
+		t.Fatalf("decode request body: %v", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/users/lookup",
+		status: http.StatusNotFound,
+		answer: map[string]string{"message": "user not found"},
+	})
+	_, err := c.LookupUser(context.Background(), "nobody")
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("got error %v, want a 404 StatusError", err)
+	}
+	if got := err.(*StatusError).Message; got != "user not found" {
+		t.Errorf("got message %q, want the grafana message", got)
+	}
+	if IsStatus(err, http.StatusUnauthorized) {
+		t.Errorf("a 404 error is not a 401 one")
+	}
+}
+
+func TestWithOrg(t *testing.T) {
+	c := newTestClient(t,
+		route{
+			method: http.MethodGet,
+			path:   "/api/folders",
+			answer: []Folder{},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("X-Grafana-Org-Id"); got != "3" {
+					t.Errorf("got org header %q, want 3", got)
+				}
+			},
+		},
+		route{
+			method: http.MethodGet,
+			path:   "/api/folders",
+			answer: []Folder{},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("X-Grafana-Org-Id"); got != "" {
+					t.Errorf("got org header %q on the client without org", got)
+				}
+			},
+		},
+	)
+	if _, err := c.WithOrg(3).ListFolders(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListFolders(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testPKI is a CA with a server and a client certificate signed by it
+type testPKI struct {
+	caPEM, serverCertPEM, serverKeyPEM, clientCertPEM, clientKeyPEM []byte
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "grafana"},
+			DNSNames:     []string{"grafana"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	pki := &testPKI{caPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})}
+	pki.serverCertPEM, pki.serverKeyPEM = issue(2, x509.ExtKeyUsageServerAuth)
+	pki.clientCertPEM, pki.clientKeyPEM = issue(3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+// newMTLSServer starts a fake grafana which requires a client certificate of the test CA
+func newMTLSServer(t *testing.T, pki *testPKI) *httptest.Server {
+	t.Helper()
+	serverCert, err := tls.X509KeyPair(pki.serverCertPEM, pki.serverKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(pki.caPEM)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Health{Database: DatabaseOK, Version: "6.5.2"})
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMTLSServer(t, pki)
+
+	withCert, err := TLSConfig(pki.caPEM, pki.clientCertPEM, pki.clientKeyPEM, "grafana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	health, err := New(server.URL, "", "", withCert).Health(context.Background())
+	if err != nil {
+		t.Fatalf("request with the client certificate failed: %v", err)
+	}
+	if health.Version != "6.5.2" {
+		t.Errorf("got version %q, want 6.5.2", health.Version)
+	}
+
+	withoutCert, err := TLSConfig(pki.caPEM, nil, nil, "grafana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(server.URL, "", "", withoutCert).Health(context.Background()); err == nil {
+		t.Errorf("request without client certificate succeeded")
+	}
+
+	wrongName, err := TLSConfig(pki.caPEM, pki.clientCertPEM, pki.clientKeyPEM, "prometheus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(server.URL, "", "", wrongName).Health(context.Background()); err == nil {
+		t.Errorf("request to a server with another name succeeded")
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newMTLSServer(t, pki)
+
+	dir, err := ioutil.TempDir("", "grafana-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string][]byte{"ca.crt": pki.caPEM, "tls.crt": pki.clientCertPEM, "tls.key": pki.clientKeyPEM}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := LoadTLSConfig(dir, "grafana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(server.URL, "", "", config).Health(context.Background()); err != nil {
+		t.Errorf("request with the mounted certificates failed: %v", err)
+	}
+}
+
+func TestTLSConfigInvalidCA(t *testing.T) {
+	if _, err := TLSConfig([]byte("not a certificate"), nil, nil, "grafana"); err == nil {
+		t.Errorf("a CA bundle without certificate is accepted")
+	}
+}
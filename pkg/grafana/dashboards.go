@@ -0,0 +1,94 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// DashboardHit is a dashboard or folder found by a search
+type DashboardHit struct {
+	ID          int64  `json:"id"`
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Type        string `json:"type"`
+	FolderID    int64  `json:"folderId,omitempty"`
+	FolderUID   string `json:"folderUid,omitempty"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+}
+
+// Dashboard is the JSON model of a dashboard and where it is stored
+type Dashboard struct {
+	Model    json.RawMessage `json:"dashboard"`
+	FolderID int64           `json:"folderId,omitempty"`
+	// Overwrite replaces a dashboard with the same uid or title
+	Overwrite bool   `json:"overwrite,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// SavedDashboard identifies a saved dashboard
+type SavedDashboard struct {
+	ID      int64  `json:"id"`
+	UID     string `json:"uid"`
+	URL     string `json:"url"`
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+}
+
+// SearchDashboards returns the dashboards of the org whose title matches query
+func (c *Client) SearchDashboards(ctx context.Context, query string) ([]DashboardHit, error) {
+	values := url.Values{"type": []string{"dash-db"}}
+	if query != "" {
+		values.Set("query", query)
+	}
+	hits := []DashboardHit{}
+	if err := c.do(ctx, http.MethodGet, "/api/search", values, nil, &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// GetDashboard returns the dashboard with the given uid
+func (c *Client) GetDashboard(ctx context.Context, uid string) (*Dashboard, error) {
+	answer := struct {
+		Dashboard json.RawMessage `json:"dashboard"`
+		Meta      struct {
+			FolderID int64 `json:"folderId"`
+		} `json:"meta"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/api/dashboards/uid/"+url.PathEscape(uid), nil, nil, &answer); err != nil {
+		return nil, err
+	}
+	return &Dashboard{Model: answer.Dashboard, FolderID: answer.Meta.FolderID}, nil
+}
+
+// SaveDashboard creates or updates a dashboard
+func (c *Client) SaveDashboard(ctx context.Context, dashboard Dashboard) (*SavedDashboard, error) {
+	saved := &SavedDashboard{}
+	if err := c.do(ctx, http.MethodPost, "/api/dashboards/db", nil, dashboard, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// DeleteDashboard deletes the dashboard with the given uid
+func (c *Client) DeleteDashboard(ctx context.Context, uid string) error {
+	return c.do(ctx, http.MethodDelete, "/api/dashboards/uid/"+url.PathEscape(uid), nil, nil, nil)
+}
@@ -0,0 +1,83 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDashboards(t *testing.T) {
+	model := json.RawMessage(`{"uid":"pods","title":"Pods"}`)
+	c := newTestClient(t,
+		route{
+			method: http.MethodGet,
+			path:   "/api/search",
+			answer: []DashboardHit{{ID: 4, UID: "pods", Title: "Pods", Type: "dash-db"}},
+			check: func(t *testing.T, r *http.Request) {
+				if q := r.URL.Query(); q.Get("query") != "Pods" || q.Get("type") != "dash-db" {
+					t.Errorf("got query %v, want the dashboards titled Pods", q)
+				}
+			},
+		},
+		route{
+			method: http.MethodGet,
+			path:   "/api/dashboards/uid/pods",
+			answer: map[string]interface{}{"dashboard": model, "meta": map[string]interface{}{"folderId": 2}},
+		},
+		route{
+			method: http.MethodPost,
+			path:   "/api/dashboards/db",
+			answer: SavedDashboard{ID: 4, UID: "pods", Status: "success", Version: 3},
+			check: func(t *testing.T, r *http.Request) {
+				dashboard := Dashboard{}
+				decodeBody(t, r, &dashboard)
+				if !dashboard.Overwrite || dashboard.FolderID != 2 || string(dashboard.Model) != string(model) {
+					t.Errorf("got dashboard %+v, want the Pods dashboard overwritten in folder 2", dashboard)
+				}
+			},
+		},
+		route{method: http.MethodDelete, path: "/api/dashboards/uid/pods"},
+	)
+	ctx := context.Background()
+	hits, err := c.SearchDashboards(ctx, "Pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].UID != "pods" {
+		t.Fatalf("got hits %+v", hits)
+	}
+	dashboard, err := c.GetDashboard(ctx, hits[0].UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.FolderID != 2 {
+		t.Errorf("got folder %d, want 2", dashboard.FolderID)
+	}
+	dashboard.Overwrite = true
+	saved, err := c.SaveDashboard(ctx, *dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != 3 {
+		t.Errorf("got version %d, want 3", saved.Version)
+	}
+	if err := c.DeleteDashboard(ctx, "pods"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,77 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Datasource is a datasource of the org of the client
+type Datasource struct {
+	ID        int64                  `json:"id,omitempty"`
+	UID       string                 `json:"uid,omitempty"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Access    string                 `json:"access"`
+	URL       string                 `json:"url"`
+	IsDefault bool                   `json:"isDefault"`
+	JSONData  map[string]interface{} `json:"jsonData,omitempty"`
+	// SecureJSONData is write only, grafana never returns it
+	SecureJSONData map[string]string `json:"secureJsonData,omitempty"`
+	ReadOnly       bool              `json:"readOnly,omitempty"`
+}
+
+// ListDatasources returns the datasources of the org
+func (c *Client) ListDatasources(ctx context.Context) ([]Datasource, error) {
+	datasources := []Datasource{}
+	if err := c.do(ctx, http.MethodGet, "/api/datasources", nil, nil, &datasources); err != nil {
+		return nil, err
+	}
+	return datasources, nil
+}
+
+// GetDatasourceByName returns the datasource with the given name
+func (c *Client) GetDatasourceByName(ctx context.Context, name string) (*Datasource, error) {
+	datasource := &Datasource{}
+	if err := c.do(ctx, http.MethodGet, "/api/datasources/name/"+url.PathEscape(name), nil, nil, datasource); err != nil {
+		return nil, err
+	}
+	return datasource, nil
+}
+
+// CreateDatasource creates a datasource and returns its id
+func (c *Client) CreateDatasource(ctx context.Context, datasource Datasource) (int64, error) {
+	answer := struct {
+		ID int64 `json:"id"`
+	}{}
+	if err := c.do(ctx, http.MethodPost, "/api/datasources", nil, datasource, &answer); err != nil {
+		return 0, err
+	}
+	return answer.ID, nil
+}
+
+// UpdateDatasource updates the datasource with the id of datasource
+func (c *Client) UpdateDatasource(ctx context.Context, datasource Datasource) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/api/datasources/%d", datasource.ID), nil, datasource, nil)
+}
+
+// DeleteDatasource deletes the datasource with the given id
+func (c *Client) DeleteDatasource(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/datasources/%d", id), nil, nil, nil)
+}
@@ -0,0 +1,71 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDatasources(t *testing.T) {
+	prometheus := Datasource{Name: "prometheus", Type: "prometheus", Access: "proxy", URL: "https://prometheus:9090"}
+	c := newTestClient(t,
+		route{
+			method: http.MethodPost,
+			path:   "/api/datasources",
+			answer: map[string]interface{}{"id": 9, "message": "Datasource added"},
+			check: func(t *testing.T, r *http.Request) {
+				ds := Datasource{}
+				decodeBody(t, r, &ds)
+				if ds.Name != "prometheus" || ds.SecureJSONData["httpHeaderValue1"] != "token" {
+					t.Errorf("got datasource %+v, want prometheus with its secure data", ds)
+				}
+			},
+		},
+		route{method: http.MethodGet, path: "/api/datasources/name/prometheus", answer: Datasource{ID: 9, Name: "prometheus"}},
+		route{method: http.MethodPut, path: "/api/datasources/9"},
+		route{method: http.MethodGet, path: "/api/datasources", answer: []Datasource{{ID: 9, Name: "prometheus"}}},
+		route{method: http.MethodDelete, path: "/api/datasources/9"},
+	)
+	ctx := context.Background()
+	prometheus.SecureJSONData = map[string]string{"httpHeaderValue1": "token"}
+	id, err := c.CreateDatasource(ctx, prometheus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 9 {
+		t.Errorf("got id %d, want 9", id)
+	}
+	ds, err := c.GetDatasourceByName(ctx, "prometheus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.IsDefault = true
+	if err := c.UpdateDatasource(ctx, *ds); err != nil {
+		t.Fatal(err)
+	}
+	all, err := c.ListDatasources(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Errorf("got datasources %+v", all)
+	}
+	if err := c.DeleteDatasource(ctx, 9); err != nil {
+		t.Fatal(err)
+	}
+}
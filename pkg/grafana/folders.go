@@ -0,0 +1,72 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Folder is a dashboard folder of the org of the client
+type Folder struct {
+	ID    int64  `json:"id,omitempty"`
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// Version is required to update a folder
+	Version int `json:"version,omitempty"`
+}
+
+// ListFolders returns the folders of the org
+func (c *Client) ListFolders(ctx context.Context) ([]Folder, error) {
+	folders := []Folder{}
+	if err := c.do(ctx, http.MethodGet, "/api/folders", nil, nil, &folders); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// GetFolder returns the folder with the given uid
+func (c *Client) GetFolder(ctx context.Context, uid string) (*Folder, error) {
+	folder := &Folder{}
+	if err := c.do(ctx, http.MethodGet, "/api/folders/"+url.PathEscape(uid), nil, nil, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// CreateFolder creates a folder, grafana generates its uid when empty
+func (c *Client) CreateFolder(ctx context.Context, folder Folder) (*Folder, error) {
+	created := &Folder{}
+	if err := c.do(ctx, http.MethodPost, "/api/folders", nil, folder, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateFolder renames the folder with the uid of folder
+func (c *Client) UpdateFolder(ctx context.Context, folder Folder) (*Folder, error) {
+	updated := &Folder{}
+	if err := c.do(ctx, http.MethodPut, "/api/folders/"+url.PathEscape(folder.UID), nil, folder, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteFolder deletes a folder with its dashboards and alerts
+func (c *Client) DeleteFolder(ctx context.Context, uid string) error {
+	return c.do(ctx, http.MethodDelete, "/api/folders/"+url.PathEscape(uid), nil, nil, nil)
+}
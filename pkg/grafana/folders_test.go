@@ -0,0 +1,70 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestFolders(t *testing.T) {
+	c := newTestClient(t,
+		route{
+			method: http.MethodPost,
+			path:   "/api/folders",
+			answer: Folder{ID: 1, UID: "ops", Title: "Ops", Version: 1},
+			check: func(t *testing.T, r *http.Request) {
+				folder := Folder{}
+				decodeBody(t, r, &folder)
+				if folder.Title != "Ops" || folder.UID != "ops" {
+					t.Errorf("got folder %+v, want Ops", folder)
+				}
+			},
+		},
+		route{method: http.MethodGet, path: "/api/folders/ops", answer: Folder{ID: 1, UID: "ops", Title: "Ops", Version: 1}},
+		route{method: http.MethodPut, path: "/api/folders/ops", answer: Folder{ID: 1, UID: "ops", Title: "Operations", Version: 2}},
+		route{method: http.MethodGet, path: "/api/folders", answer: []Folder{{ID: 1, UID: "ops", Title: "Operations"}}},
+		route{method: http.MethodDelete, path: "/api/folders/ops"},
+	)
+	ctx := context.Background()
+	created, err := c.CreateFolder(ctx, Folder{UID: "ops", Title: "Ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	folder, err := c.GetFolder(ctx, created.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folder.Title = "Operations"
+	updated, err := c.UpdateFolder(ctx, *folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("got version %d, want 2", updated.Version)
+	}
+	folders, err := c.ListFolders(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 1 || folders[0].Title != "Operations" {
+		t.Errorf("got folders %+v", folders)
+	}
+	if err := c.DeleteFolder(ctx, "ops"); err != nil {
+		t.Fatal(err)
+	}
+}
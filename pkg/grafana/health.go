@@ -0,0 +1,41 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+)
+
+// Health is the answer of the grafana health endpoint
+type Health struct {
+	Commit   string `json:"commit"`
+	Database string `json:"database"`
+	Version  string `json:"version"`
+}
+
+// DatabaseOK is the database status of a healthy grafana
+const DatabaseOK = "ok"
+
+// Health returns the version of grafana and the status of its database.
+// Grafana answers 503 when the database is not reachable.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	health := &Health{}
+	if err := c.do(ctx, http.MethodGet, "/api/health", nil, nil, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
@@ -0,0 +1,49 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/health",
+		answer: Health{Commit: "abc123", Database: DatabaseOK, Version: "6.5.2"},
+	})
+	health, err := c.Health(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Database != DatabaseOK || health.Version != "6.5.2" || health.Commit != "abc123" {
+		t.Errorf("got health %+v", health)
+	}
+}
+
+func TestHealthDatabaseFailing(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/health",
+		status: http.StatusServiceUnavailable,
+		answer: Health{Database: "failing", Version: "6.5.2"},
+	})
+	if _, err := c.Health(context.Background()); !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("got error %v, want a 503 StatusError", err)
+	}
+}
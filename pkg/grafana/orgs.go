@@ -0,0 +1,102 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// MainOrgID is the id of the org grafana creates on its first start
+const MainOrgID int64 = 1
+
+// Org is a grafana organization
+type Org struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListOrgs returns all the orgs
+func (c *Client) ListOrgs(ctx context.Context) ([]Org, error) {
+	orgs := []Org{}
+	if err := c.do(ctx, http.MethodGet, "/api/orgs", nil, nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// LookupOrg returns the org with the given name
+func (c *Client) LookupOrg(ctx context.Context, name string) (*Org, error) {
+	org := &Org{}
+	if err := c.do(ctx, http.MethodGet, "/api/orgs/name/"+url.PathEscape(name), nil, nil, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// CreateOrg creates an org and returns its id
+func (c *Client) CreateOrg(ctx context.Context, name string) (int64, error) {
+	answer := struct {
+		OrgID int64 `json:"orgId"`
+	}{}
+	body := map[string]string{"name": name}
+	if err := c.do(ctx, http.MethodPost, "/api/orgs", nil, body, &answer); err != nil {
+		return 0, err
+	}
+	return answer.OrgID, nil
+}
+
+// DeleteOrg deletes an org with its dashboards, folders and datasources
+func (c *Client) DeleteOrg(ctx context.Context, orgID int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/orgs/%d", orgID), nil, nil, nil)
+}
+
+// OrgUser is the membership of a user in an org
+type OrgUser struct {
+	OrgID  int64  `json:"orgId"`
+	UserID int64  `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"`
+}
+
+// OrgUsers returns the members of an org
+func (c *Client) OrgUsers(ctx context.Context, orgID int64) ([]OrgUser, error) {
+	users := []OrgUser{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/orgs/%d/users", orgID), nil, nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// AddOrgUser adds an existing user to an org with the given role
+func (c *Client) AddOrgUser(ctx context.Context, orgID int64, login, role string) error {
+	body := map[string]string{"loginOrEmail": login, "role": role}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/orgs/%d/users", orgID), nil, body, nil)
+}
+
+// UpdateOrgUser changes the role of a user in an org
+func (c *Client) UpdateOrgUser(ctx context.Context, orgID, userID int64, role string) error {
+	body := map[string]string{"role": role}
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/api/orgs/%d/users/%d", orgID, userID), nil, body, nil)
+}
+
+// RemoveOrgUser removes a user from an org
+func (c *Client) RemoveOrgUser(ctx context.Context, orgID, userID int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/orgs/%d/users/%d", orgID, userID), nil, nil, nil)
+}
@@ -0,0 +1,114 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestLookupOrgEscapesName(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/orgs/name/team a",
+		answer: Org{ID: 2, Name: "team a"},
+		check: func(t *testing.T, r *http.Request) {
+			if r.URL.RawPath != "" && r.URL.RawPath != "/api/orgs/name/team%20a" {
+				t.Errorf("got raw path %q, want the name escaped", r.URL.RawPath)
+			}
+		},
+	})
+	org, err := c.LookupOrg(context.Background(), "team a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org.ID != 2 {
+		t.Errorf("got org %+v, want id 2", org)
+	}
+}
+
+func TestCreateOrg(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodPost,
+		path:   "/api/orgs",
+		answer: map[string]interface{}{"orgId": 3, "message": "Organization created"},
+		check: func(t *testing.T, r *http.Request) {
+			body := map[string]string{}
+			decodeBody(t, r, &body)
+			if body["name"] != "dev" {
+				t.Errorf("got body %v, want the org name", body)
+			}
+		},
+	})
+	id, err := c.CreateOrg(context.Background(), "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 3 {
+		t.Errorf("got id %d, want 3", id)
+	}
+}
+
+func TestOrgMembers(t *testing.T) {
+	c := newTestClient(t,
+		route{
+			method: http.MethodPost,
+			path:   "/api/orgs/3/users",
+			check: func(t *testing.T, r *http.Request) {
+				body := map[string]string{}
+				decodeBody(t, r, &body)
+				if body["loginOrEmail"] != "alice" || body["role"] != "Editor" {
+					t.Errorf("got body %v, want alice as editor", body)
+				}
+			},
+		},
+		route{
+			method: http.MethodPatch,
+			path:   "/api/orgs/3/users/7",
+			check: func(t *testing.T, r *http.Request) {
+				body := map[string]string{}
+				decodeBody(t, r, &body)
+				if body["role"] != "Admin" {
+					t.Errorf("got body %v, want the admin role", body)
+				}
+			},
+		},
+		route{
+			method: http.MethodGet,
+			path:   "/api/orgs/3/users",
+			answer: []OrgUser{{OrgID: 3, UserID: 7, Login: "alice", Role: "Admin"}},
+		},
+		route{method: http.MethodDelete, path: "/api/orgs/3/users/7"},
+	)
+	ctx := context.Background()
+	if err := c.AddOrgUser(ctx, 3, "alice", "Editor"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpdateOrgUser(ctx, 3, 7, "Admin"); err != nil {
+		t.Fatal(err)
+	}
+	users, err := c.OrgUsers(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Role != "Admin" {
+		t.Errorf("got members %+v, want alice as admin", users)
+	}
+	if err := c.RemoveOrgUser(ctx, 3, 7); err != nil {
+		t.Fatal(err)
+	}
+}
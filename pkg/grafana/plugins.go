@@ -0,0 +1,47 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Plugin is a plugin loaded by grafana
+type Plugin struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Enabled   bool       `json:"enabled"`
+	Signature string     `json:"signature,omitempty"`
+	Info      PluginInfo `json:"info"`
+}
+
+// PluginInfo describes a plugin
+type PluginInfo struct {
+	Version string `json:"version"`
+}
+
+// Plugins returns the plugins grafana loaded, core plugins excluded
+func (c *Client) Plugins(ctx context.Context) ([]Plugin, error) {
+	plugins := []Plugin{}
+	query := url.Values{"core": []string{"0"}}
+	if err := c.do(ctx, http.MethodGet, "/api/plugins", query, nil, &plugins); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
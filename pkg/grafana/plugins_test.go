@@ -0,0 +1,44 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/plugins",
+		answer: []Plugin{
+			{ID: "grafana-piechart-panel", Type: "panel", Signature: "valid", Info: PluginInfo{Version: "1.6.1"}},
+		},
+		check: func(t *testing.T, r *http.Request) {
+			if core := r.URL.Query().Get("core"); core != "0" {
+				t.Errorf("got core=%q, want the core plugins left out", core)
+			}
+		},
+	})
+	plugins, err := c.Plugins(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 1 || plugins[0].ID != "grafana-piechart-panel" || plugins[0].Info.Version != "1.6.1" {
+		t.Errorf("got plugins %+v", plugins)
+	}
+}
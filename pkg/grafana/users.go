@@ -0,0 +1,136 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// User is a grafana user
+type User struct {
+	ID             int64  `json:"id"`
+	Login          string `json:"login"`
+	Email          string `json:"email,omitempty"`
+	Name           string `json:"name,omitempty"`
+	IsGrafanaAdmin bool   `json:"isGrafanaAdmin,omitempty"`
+	IsDisabled     bool   `json:"isDisabled,omitempty"`
+}
+
+// LookupUser returns the user with the given login or email
+func (c *Client) LookupUser(ctx context.Context, loginOrEmail string) (*User, error) {
+	user := &User{}
+	query := url.Values{"loginOrEmail": []string{loginOrEmail}}
+	if err := c.do(ctx, http.MethodGet, "/api/users/lookup", query, nil, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserPassword changes the password of a user, as the grafana admin
+func (c *Client) SetUserPassword(ctx context.Context, id int64, password string) error {
+	body := map[string]string{"password": password}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/api/admin/users/%d/password", id), nil, body, nil)
+}
+
+// NewUser is a user created by the grafana admin
+type NewUser struct {
+	Login    string `json:"login"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Password string `json:"password"`
+}
+
+// CreateUser creates a user and returns its id. Grafana adds the user to
+// the main org with the auto_assign_org_role role.
+func (c *Client) CreateUser(ctx context.Context, user NewUser) (int64, error) {
+	answer := struct {
+		ID int64 `json:"id"`
+	}{}
+	if err := c.do(ctx, http.MethodPost, "/api/admin/users", nil, user, &answer); err != nil {
+		return 0, err
+	}
+	return answer.ID, nil
+}
+
+// UserOrg is the membership of a user in an org
+type UserOrg struct {
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+}
+
+// UserOrgs returns the orgs a user is a member of
+func (c *Client) UserOrgs(ctx context.Context, id int64) ([]UserOrg, error) {
+	orgs := []UserOrg{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/users/%d/orgs", id), nil, nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// UserPage is one page of a user search
+type UserPage struct {
+	TotalCount int64  `json:"totalCount"`
+	Users      []User `json:"users"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"perPage"`
+}
+
+// SearchUsers returns the page page, from 1, of the users matching query,
+// perPage users at a time.
+func (c *Client) SearchUsers(ctx context.Context, query string, page, perPage int) (*UserPage, error) {
+	values := url.Values{
+		"page":    []string{strconv.Itoa(page)},
+		"perpage": []string{strconv.Itoa(perPage)},
+	}
+	if query != "" {
+		values.Set("query", query)
+	}
+	result := &UserPage{}
+	if err := c.do(ctx, http.MethodGet, "/api/users/search", values, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AllUsers pages through all the users, perPage users at a time
+func (c *Client) AllUsers(ctx context.Context, perPage int) ([]User, error) {
+	users := []User{}
+	for page := 1; ; page++ {
+		result, err := c.SearchUsers(ctx, "", page, perPage)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, result.Users...)
+		if len(result.Users) < perPage || int64(len(users)) >= result.TotalCount {
+			return users, nil
+		}
+	}
+}
+
+// DeleteUser deletes a user
+func (c *Client) DeleteUser(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/admin/users/%d", id), nil, nil, nil)
+}
+
+// DisableUser prevents a user from logging in while keeping its settings
+func (c *Client) DisableUser(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/admin/users/%d/disable", id), nil, nil, nil)
+}
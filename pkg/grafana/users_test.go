@@ -0,0 +1,138 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package grafana
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestLookupUser(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/users/lookup",
+		answer: User{ID: 7, Login: "alice"},
+		check: func(t *testing.T, r *http.Request) {
+			if got := r.URL.Query().Get("loginOrEmail"); got != "alice" {
+				t.Errorf("got loginOrEmail %q, want alice", got)
+			}
+		},
+	})
+	user, err := c.LookupUser(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 7 || user.Login != "alice" {
+		t.Errorf("got user %+v, want alice with id 7", user)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodPost,
+		path:   "/api/admin/users",
+		answer: map[string]interface{}{"id": 12, "message": "User created"},
+		check: func(t *testing.T, r *http.Request) {
+			user := NewUser{}
+			decodeBody(t, r, &user)
+			if user.Login != "bob" || user.Password != "pw" {
+				t.Errorf("got new user %+v, want bob with his password", user)
+			}
+		},
+	})
+	id, err := c.CreateUser(context.Background(), NewUser{Login: "bob", Password: "pw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 12 {
+		t.Errorf("got id %d, want 12", id)
+	}
+}
+
+func TestSetUserPassword(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodPut,
+		path:   "/api/admin/users/1/password",
+		check: func(t *testing.T, r *http.Request) {
+			body := map[string]string{}
+			decodeBody(t, r, &body)
+			if body["password"] != "new" {
+				t.Errorf("got body %v, want the new password", body)
+			}
+		},
+	})
+	if err := c.SetUserPassword(context.Background(), 1, "new"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllUsersPages(t *testing.T) {
+	page := func(number int, logins ...string) route {
+		users := []User{}
+		for _, login := range logins {
+			users = append(users, User{Login: login})
+		}
+		return route{
+			method: http.MethodGet,
+			path:   "/api/users/search",
+			answer: UserPage{TotalCount: 5, Users: users, Page: number, PerPage: 2},
+			check: func(t *testing.T, r *http.Request) {
+				query := r.URL.Query()
+				if query.Get("perpage") != "2" || query.Get("page") != strconv.Itoa(number) {
+					t.Errorf("got query %v, want page %d of 2 users", query, number)
+				}
+			},
+		}
+	}
+	c := newTestClient(t, page(1, "a", "b"), page(2, "c", "d"), page(3, "e"))
+	users, err := c.AllUsers(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 5 || users[4].Login != "e" {
+		t.Errorf("got users %+v, want the 5 users of the 3 pages", users)
+	}
+}
+
+func TestUserOrgs(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodGet,
+		path:   "/api/users/4/orgs",
+		answer: []UserOrg{{OrgID: 1, Name: "Main Org.", Role: "Viewer"}},
+	})
+	orgs, err := c.UserOrgs(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orgs) != 1 || orgs[0].OrgID != 1 || orgs[0].Role != "Viewer" {
+		t.Errorf("got orgs %+v, want the main org", orgs)
+	}
+}
+
+func TestDisableAndDeleteUser(t *testing.T) {
+	c := newTestClient(t,
+		route{method: http.MethodPost, path: "/api/admin/users/5/disable"},
+		route{method: http.MethodDelete, path: "/api/admin/users/5"},
+	)
+	if err := c.DisableUser(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteUser(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,151 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package iam
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds every request sent to IAM
+const DefaultTimeout = time.Second * 30
+
+// Client reads the IAM users and their namespaces as the IAM admin
+type Client struct {
+	providerURL   string
+	managementURL string
+	user          string
+	password      string
+	token         string
+	httpClient    *http.Client
+}
+
+// Namespace is a namespace an IAM user gets through its teams
+type Namespace struct {
+	NamespaceID string `json:"namespaceId"`
+	// HighestRole is the highest role of the user in the namespace, such as Administrator
+	HighestRole string `json:"highestRole,omitempty"`
+	// Actions are the allowed actions when IAM reports no role, such as CRUD
+	Actions string `json:"actions,omitempty"`
+}
+
+// User is an IAM user
+type User struct {
+	UserID string `json:"userId"`
+}
+
+// New returns a client of the identity provider at providerURL and of the
+// identity management at managementURL, logged in with the IAM admin
+// user and password.
+func New(providerURL, managementURL, user, password string, tlsConfig *tls.Config) *Client {
+	return &Client{
+		providerURL:   strings.TrimSuffix(providerURL, "/"),
+		managementURL: strings.TrimSuffix(managementURL, "/"),
+		user:          user,
+		password:      password,
+		httpClient: &http.Client{
+			Timeout:   DefaultTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+}
+
+// Login gets the access token sent with the next requests
+func (c *Client) Login(ctx context.Context) error {
+	form := url.Values{
+		"grant_type": []string{"password"},
+		"username":   []string{c.user},
+		"password":   []string{c.password},
+		"scope":      []string{"openid"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.providerURL+"/v1/auth/identitytoken",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	answer := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := c.send(req, &answer); err != nil {
+		return err
+	}
+	if answer.AccessToken == "" {
+		return fmt.Errorf("IAM returned no access token")
+	}
+	c.token = answer.AccessToken
+	return nil
+}
+
+// Users returns all the IAM users
+func (c *Client) Users(ctx context.Context) ([]User, error) {
+	users := []User{}
+	if err := c.get(ctx, "/identity/api/v1/users", nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UserNamespaces returns the namespaces the teams of a user give access to
+func (c *Client) UserNamespaces(ctx context.Context, userID string) ([]Namespace, error) {
+	namespaces := []Namespace{}
+	query := url.Values{"resourceType": []string{"namespace"}}
+	path := "/identity/api/v1/users/" + url.PathEscape(userID) + "/getTeamResources"
+	if err := c.get(ctx, path, query, &namespaces); err != nil {
+		return nil, err
+	}
+	return namespaces, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if c.token == "" {
+		return fmt.Errorf("not logged in to IAM")
+	}
+	u := c.managementURL + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return c.send(req, out)
+}
+
+func (c *Client) send(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("IAM %s %s returned %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,238 @@
+//
+// Copyright 2021 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package iam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// route is the request a test expects and the answer of the fake IAM
+type route struct {
+	method string
+	path   string
+	status int
+	answer interface{}
+	// raw is sent as is instead of answer
+	raw string
+	// check inspects the request
+	check func(t *testing.T, r *http.Request)
+}
+
+// newTestClient starts a fake IAM serving routes in order, as both the
+// identity provider and the identity management
+func newTestClient(t *testing.T, routes ...route) *Client {
+	t.Helper()
+	next := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if next >= len(routes) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rt := routes[next]
+		next++
+		if r.Method != rt.method || r.URL.Path != rt.path {
+			t.Errorf("got request %s %s, want %s %s", r.Method, r.URL.Path, rt.method, rt.path)
+		}
+		if rt.check != nil {
+			rt.check(t, r)
+		}
+		status := rt.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		switch {
+		case rt.raw != "":
+			_, _ = w.Write([]byte(rt.raw))
+		case rt.answer != nil:
+			_ = json.NewEncoder(w).Encode(rt.answer)
+		}
+	}))
+	t.Cleanup(func() {
+		server.Close()
+		if next != len(routes) {
+			t.Errorf("got %d requests, want %d", next, len(routes))
+		}
+	})
+	return New(server.URL+"/", server.URL, "admin", "secret", nil)
+}
+
+// login is the token exchange of a successful Login
+var login = route{
+	method: http.MethodPost,
+	path:   "/v1/auth/identitytoken",
+	answer: map[string]string{"access_token": "token"},
+}
+
+// bearer checks the request carries the token of login
+func bearer(t *testing.T, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("got authorization %q, want the access token", got)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	c := newTestClient(t, route{
+		method: http.MethodPost,
+		path:   "/v1/auth/identitytoken",
+		answer: map[string]string{"access_token": "token", "token_type": "Bearer"},
+		check: func(t *testing.T, r *http.Request) {
+			if ct := r.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+				t.Errorf("got content type %q, want a form", ct)
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range map[string]string{
+				"grant_type": "password",
+				"username":   "admin",
+				"password":   "secret",
+				"scope":      "openid",
+			} {
+				if got := r.PostForm.Get(key); got != want {
+					t.Errorf("got %s %q, want %q", key, got, want)
+				}
+			}
+		},
+	})
+	if err := c.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if c.token != "token" {
+		t.Errorf("got token %q, want the access token", c.token)
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		route route
+		want  string
+	}{
+		{
+			name:  "rejected credentials",
+			route: route{method: http.MethodPost, path: "/v1/auth/identitytoken", status: http.StatusUnauthorized, raw: "invalid credentials\n"},
+			want:  "IAM POST /v1/auth/identitytoken returned 401: invalid credentials",
+		},
+		{
+			name:  "no token",
+			route: route{method: http.MethodPost, path: "/v1/auth/identitytoken", answer: map[string]string{}},
+			want:  "IAM returned no access token",
+		},
+		{
+			name:  "not JSON",
+			route: route{method: http.MethodPost, path: "/v1/auth/identitytoken", raw: "<html></html>"},
+			want:  "invalid character",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.route)
+			err := c.Login(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want %q", err, tt.want)
+			}
+			if c.token != "" {
+				t.Errorf("got token %q after a failed login", c.token)
+			}
+		})
+	}
+}
+
+func TestNotLoggedIn(t *testing.T) {
+	c := newTestClient(t)
+	if _, err := c.Users(context.Background()); err == nil {
+		t.Fatal("got no error before the login")
+	}
+}
+
+func TestUsers(t *testing.T) {
+	c := newTestClient(t, login, route{
+		method: http.MethodGet,
+		path:   "/identity/api/v1/users",
+		raw:    `[{"userId":"alice","name":"Alice"},{"userId":"bob"}]`,
+		check:  bearer,
+	})
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	users, err := c.Users(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []User{{UserID: "alice"}, {UserID: "bob"}}; !reflect.DeepEqual(users, want) {
+		t.Errorf("got users %+v, want %+v", users, want)
+	}
+}
+
+func TestUsersError(t *testing.T) {
+	c := newTestClient(t, login, route{
+		method: http.MethodGet,
+		path:   "/identity/api/v1/users",
+		status: http.StatusInternalServerError,
+		raw:    "database unavailable",
+	})
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	users, err := c.Users(ctx)
+	if err == nil || !strings.Contains(err.Error(), "returned 500: database unavailable") {
+		t.Fatalf("got error %v, want the 500 answer", err)
+	}
+	if users != nil {
+		t.Errorf("got users %+v with the error", users)
+	}
+}
+
+func TestUserNamespaces(t *testing.T) {
+	c := newTestClient(t, login, route{
+		method: http.MethodGet,
+		path:   "/identity/api/v1/users/ldap/jane/getTeamResources",
+		raw: `[{"namespaceId":"dev","highestRole":"Administrator","scope":"namespace"},
+			{"namespaceId":"prod","actions":"R"}]`,
+		check: func(t *testing.T, r *http.Request) {
+			bearer(t, r)
+			if got := r.URL.EscapedPath(); got != "/identity/api/v1/users/ldap%2Fjane/getTeamResources" {
+				t.Errorf("got path %q, want the user id escaped", got)
+			}
+			if got := r.URL.Query().Get("resourceType"); got != "namespace" {
+				t.Errorf("got resource type %q, want namespace", got)
+			}
+		},
+	})
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	namespaces, err := c.UserNamespaces(ctx, "ldap/jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Namespace{{NamespaceID: "dev", HighestRole: "Administrator"}, {NamespaceID: "prod", Actions: "R"}}
+	if !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("got namespaces %+v, want %+v", namespaces, want)
+	}
+}